@@ -1,6 +1,6 @@
 module github.com/bep/logg/benchmarks
 
-go 1.24.0
+go 1.25
 
 replace github.com/bep/logg => ../
 
@@ -25,5 +25,5 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/goleak v1.1.12 // indirect
-	golang.org/x/sys v0.40.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
 )
@@ -22,6 +22,7 @@ package benchmarks
 
 import (
 	"io"
+	"time"
 
 	"github.com/bep/logg"
 	"github.com/bep/logg/handlers/json"
@@ -60,3 +61,25 @@ func fakeLognFields() logg.FieldsFunc {
 		}
 	}
 }
+
+func fakeLoggScalarFields() logg.Fields {
+	return logg.Fields{
+		logg.Int("int", _tenInts[0]),
+		logg.Str("string", _tenStrings[0]),
+		logg.Bool("bool", true),
+		logg.Duration("duration", time.Second),
+		logg.Time("time", _tenTimes[0]),
+		logg.Err(errExample),
+	}
+}
+
+func fakeLoggScalarAnyFields() logg.Fields {
+	return logg.Fields{
+		{Name: "int", Value: _tenInts[0]},
+		{Name: "string", Value: _tenStrings[0]},
+		{Name: "bool", Value: true},
+		{Name: "duration", Value: time.Second},
+		{Name: "time", Value: _tenTimes[0]},
+		{Name: "error", Value: errExample},
+	}
+}
@@ -396,3 +396,45 @@ func BenchmarkAddingFields(b *testing.B) {
 		})
 	})
 }
+
+func BenchmarkAddingScalarFields(b *testing.B) {
+	b.Logf("Logging with additional scalar fields at each log site.")
+	b.Run("bep/logg", func(b *testing.B) {
+		logger := newLoggLog()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				message := logg.StringFunc(func() string { return getMessage(0) })
+				logger.WithFields(fakeLoggScalarFields()).Log(message)
+			}
+		})
+	})
+	b.Run("bep/logg.Any", func(b *testing.B) {
+		logger := newLoggLog()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				message := logg.StringFunc(func() string { return getMessage(0) })
+				logger.WithFields(fakeLoggScalarAnyFields()).Log(message)
+			}
+		})
+	})
+	b.Run("uber-go/zap", func(b *testing.B) {
+		logger := newZapLogger()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info(getMessage(0), fakeScalarFields()...)
+			}
+		})
+	})
+	b.Run("rs/zerolog", func(b *testing.B) {
+		logger := newZerolog()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				fakeZerologScalarFields(logger.Info()).Msg(getMessage(0))
+			}
+		})
+	})
+}
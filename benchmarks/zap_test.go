@@ -23,6 +23,7 @@ package benchmarks
 import (
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"go.uber.org/multierr"
@@ -117,6 +118,22 @@ func fakeFields() []zap.Field {
 	}
 }
 
+func newZapLogger() *zap.Logger {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(zapcore.NewCore(enc, zapcore.AddSync(io.Discard), zap.DebugLevel))
+}
+
+func fakeScalarFields() []zap.Field {
+	return []zap.Field{
+		zap.Int("int", _tenInts[0]),
+		zap.String("string", _tenStrings[0]),
+		zap.Bool("bool", true),
+		zap.Duration("duration", time.Second),
+		zap.Time("time", _tenTimes[0]),
+		zap.Error(errExample),
+	}
+}
+
 func fakeSugarFields() []interface{} {
 	return []interface{}{
 		"int", _tenInts[0],
@@ -22,6 +22,7 @@ package benchmarks
 
 import (
 	"io"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -60,6 +61,16 @@ func fakeZerologFields(e *zerolog.Event) *zerolog.Event {
 		Err(errExample)
 }
 
+func fakeZerologScalarFields(e *zerolog.Event) *zerolog.Event {
+	return e.
+		Int("int", _tenInts[0]).
+		Str("string", _tenStrings[0]).
+		Bool("bool", true).
+		Dur("duration", time.Second).
+		Time("time", _tenTimes[0]).
+		Err(errExample)
+}
+
 func fakeZerologContext(c zerolog.Context) zerolog.Context {
 	return c.
 		Int("int", _tenInts[0]).
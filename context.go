@@ -0,0 +1,16 @@
+package logg
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx with l stored in it.
+func NewContext(ctx context.Context, l LevelLogger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the LevelLogger stored in ctx by NewContext, if any.
+func FromContext(ctx context.Context) (LevelLogger, bool) {
+	l, ok := ctx.Value(contextKey{}).(LevelLogger)
+	return l, ok
+}
@@ -0,0 +1,54 @@
+package logg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/memory"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestContext(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	_, found := logg.FromContext(context.Background())
+	qt.Assert(t, found, qt.IsFalse)
+
+	ctx := logg.NewContext(context.Background(), l.WithLevel(logg.LevelInfo).WithField("request", "r1"))
+	ll, found := logg.FromContext(ctx)
+	qt.Assert(t, found, qt.IsTrue)
+
+	ll.Log(logg.String("hello"))
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{{Name: "request", Value: "r1"}})
+}
+
+type ctxKey string
+
+type contextHandler struct {
+	values []any
+}
+
+func (h *contextHandler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+func (h *contextHandler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	h.values = append(h.values, ctx.Value(ctxKey("trace")))
+	return nil
+}
+
+func TestEntry_WithContext(t *testing.T) {
+	h := &contextHandler{}
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+	info := l.WithLevel(logg.LevelInfo)
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t1")
+	info.WithContext(ctx).WithField("a", "b").Log(logg.String("with context"))
+	info.Log(logg.String("without context"))
+
+	qt.Assert(t, info.Context(), qt.Equals, context.Background())
+	qt.Assert(t, h.values, qt.DeepEquals, []any{"t1", nil})
+}
@@ -1,8 +1,8 @@
 package logg
 
 import (
+	"context"
 	"fmt"
-	"strings"
 	"time"
 )
 
@@ -14,13 +14,18 @@ var (
 // Entry represents a single log entry at a given log level.
 type Entry struct {
 	logger *logger
+	ctx    context.Context
 
-	Level     Level     `json:"level"`
-	Timestamp time.Time `json:"timestamp"`
-	Fields    Fields    `json:"fields,omitempty"`
-	Message   string    `json:"message"`
+	Level      Level     `json:"level"`
+	LoggerName string    `json:"logger,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Fields     Fields    `json:"fields,omitempty"`
+	Message    string    `json:"message"`
+	Source     Frame     `json:"source,omitzero"`
+	Stack      []Frame   `json:"stack,omitempty"`
 
 	fieldsAddedCounter int
+	groups             []string // Set by WithGroup.
 }
 
 // NewEntry returns a new entry for `log`.
@@ -35,12 +40,30 @@ func (e Entry) WithLevel(level Level) *Entry {
 	return &e
 }
 
+// WithContext returns a new entry with ctx set.
+// The context is passed on to the handlers, see ContextHandler.
+func (e Entry) WithContext(ctx context.Context) *Entry {
+	e.ctx = ctx
+	return &e
+}
+
+// Context returns the context set with WithContext, or context.Background() if not set.
+func (e *Entry) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
 func (e *Entry) WithFields(fielder Fielder) *Entry {
 	if e.isLevelDisabled() {
 		return e
 	}
 	x := *e
 	fields := fielder.Fields()
+	if len(x.groups) > 0 {
+		fields = x.nest(fields)
+	}
 	x.fieldsAddedCounter += len(fields)
 	x.Fields = append(x.Fields, fields...)
 	if x.fieldsAddedCounter > 100 {
@@ -52,11 +75,32 @@ func (e *Entry) WithFields(fielder Fielder) *Entry {
 	return &x
 }
 
+// WithGroup returns a new entry where the fields added after this call are
+// put in a group with the given name, see Group.
+// Groups can be nested, e.g. WithGroup("http").WithGroup("req").
+func (e *Entry) WithGroup(name string) *Entry {
+	if name == "" || e.isLevelDisabled() {
+		return e
+	}
+	x := *e
+	x.groups = append(x.groups[:len(x.groups):len(x.groups)], name)
+	return &x
+}
+
+// nest puts fields in the groups set by WithGroup.
+func (e *Entry) nest(fields Fields) Fields {
+	f := Group(e.groups[len(e.groups)-1], append(Fields(nil), fields...)...)
+	for i := len(e.groups) - 2; i >= 0; i-- {
+		f = Group(e.groups[i], f)
+	}
+	return Fields{f}
+}
+
 func (e *Entry) WithField(key string, value any) *Entry {
 	if e.isLevelDisabled() {
 		return e
 	}
-	return e.WithFields(Fields{{key, value}})
+	return e.WithFields(Fields{{Name: key, Value: value}})
 }
 
 func (e *Entry) WithDuration(d time.Duration) *Entry {
@@ -70,6 +114,9 @@ func (e *Entry) WithDuration(d time.Duration) *Entry {
 //
 // The given error may implement .Fielder, if it does the method
 // will add all its `.Fields()` into the returned entry.
+//
+// If err, or any error it wraps, carries a stack trace (see StackTracer),
+// the deepest stack found is set in Stack.
 func (e *Entry) WithError(err error) *Entry {
 	if err == nil || e.isLevelDisabled() {
 		return e
@@ -77,19 +124,8 @@ func (e *Entry) WithError(err error) *Entry {
 
 	ctx := e.WithField("error", err.Error())
 
-	if s, ok := err.(stackTracer); ok {
-		frame := s.StackTrace()[0]
-
-		name := fmt.Sprintf("%n", frame)
-		file := fmt.Sprintf("%+s", frame)
-		line := fmt.Sprintf("%d", frame)
-
-		parts := strings.Split(file, "\n\t")
-		if len(parts) > 1 {
-			file = parts[1]
-		}
-
-		ctx = ctx.WithField("source", fmt.Sprintf("%s: %s:%s", name, file, line))
+	if stack := errorStack(err); stack != nil {
+		ctx.Stack = stack
 	}
 
 	if f, ok := err.(Fielder); ok {
@@ -99,8 +135,13 @@ func (e *Entry) WithError(err error) *Entry {
 	return ctx
 }
 
+// IsLevelEnabled reports whether e's level is at or above the logger's level.
+func (e *Entry) IsLevelEnabled() bool {
+	return !e.isLevelDisabled()
+}
+
 func (e *Entry) isLevelDisabled() bool {
-	return e.Level < e.logger.Level
+	return e.Level < e.logger.level.Level()
 }
 
 // Log a message at the given level.
@@ -116,46 +157,107 @@ func (e *Entry) Logf(format string, a ...any) {
 
 }
 
-// Clone returns a new Entry with the same fields.
+// Clone returns a new Entry with a copy of the fields,
+// including the fields in groups.
 func (e *Entry) Clone() *Entry {
 	x := *e
-	x.Fields = make(Fields, len(e.Fields))
-	copy(x.Fields, e.Fields)
+	x.Fields = e.Fields.clone()
 	return &x
 }
 
 func (e *Entry) reset() {
 	e.logger = nil
+	e.ctx = nil
 	e.Level = 0
+	e.LoggerName = ""
 	e.Fields = e.Fields[:0]
 	e.Message = ""
+	e.Source = Frame{}
+	e.Stack = nil
 	e.Timestamp = time.Time{}
+	e.groups = nil
 }
 
 // Remove any early entries with the same name.
+// Groups with the same name are merged, see mergeFields.
 func (e *Entry) mergeFields() {
+	e.Fields = mergeFields(e.Fields)
+}
+
+// mergeFields removes any early fields with the same name, in place.
+// Groups with the same name following each other (ignoring fields with other
+// names) are merged into one group at the position of the last one,
+// and the fields in groups are merged recursively.
+// The groups are always copied, as the fields in them may be shared with
+// other entries.
+func mergeFields(fields Fields) Fields {
 	n := 0
-	for i, f := range e.Fields {
+	for i, f := range fields {
+		if f.kind == KindGroup {
+			return mergeFieldsWithGroups(fields)
+		}
 		keep := true
-		for j := i + 1; j < len(e.Fields); j++ {
-			if e.Fields[j].Name == f.Name {
+		for j := i + 1; j < len(fields); j++ {
+			if fields[j].Name == f.Name {
 				keep = false
 				break
 			}
 		}
 		if keep {
-			e.Fields[n] = f
+			fields[n] = f
 			n++
 		}
 	}
-	e.Fields = e.Fields[:n]
+	return fields[:n]
+}
+
+func mergeFieldsWithGroups(fields Fields) Fields {
+	merged := make(Fields, 0, len(fields))
+	for i, f := range fields {
+		keep := true
+		for j := i + 1; j < len(fields); j++ {
+			if fields[j].Name == f.Name {
+				keep = false
+				break
+			}
+		}
+		if !keep {
+			continue
+		}
+		if f.kind == KindGroup {
+			// Find the first of the groups to merge.
+			start := i
+			for j := i - 1; j >= 0; j-- {
+				if fields[j].Name == f.Name {
+					if fields[j].kind != KindGroup {
+						break
+					}
+					start = j
+				}
+			}
+			var members Fields
+			for j := start; j <= i; j++ {
+				if fields[j].Name == f.Name {
+					members = append(members, fields[j].Group()...)
+				}
+			}
+			f = Group(f.Name, mergeFields(members)...)
+		}
+		merged = append(merged, f)
+	}
+	return fields[:copy(fields, merged)]
 }
 
 // finalize populates dst with Level and  Fields merged from e and Message and Timestamp set.
+// Any Valuer values in the fields are resolved.
+// Note that mergeFields copies the groups, so they can be modified.
 func (e *Entry) finalize(dst *Entry, msg string) {
 	dst.Message = msg
+	dst.ctx = e.ctx
 	dst.Timestamp = e.logger.Clock.Now()
 	dst.Level = e.Level
+	dst.LoggerName = e.logger.name
+	dst.Stack = e.Stack
 	if cap(dst.Fields) < len(e.Fields) {
 		dst.Fields = make(Fields, len(e.Fields))
 	} else {
@@ -163,4 +265,5 @@ func (e *Entry) finalize(dst *Entry, msg string) {
 	}
 	copy(dst.Fields, e.Fields)
 	dst.mergeFields()
+	dst.Fields.resolveValues()
 }
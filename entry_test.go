@@ -1,7 +1,10 @@
 package logg_test
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -15,19 +18,19 @@ func TestEntry_WithFields(t *testing.T) {
 	h := memory.New()
 	a := logg.New(logg.Options{Handler: h, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
 
-	b := a.WithFields(logg.Fields{{"foo", "bar"}})
+	b := a.WithFields(logg.Fields{{Name: "foo", Value: "bar"}})
 
-	c := a.WithFields(logg.Fields{{"foo", "hello"}, {"bar", "world"}})
-	d := c.WithFields(logg.Fields{{"baz", "jazz"}})
-	qt.Assert(t, b.Fields, qt.DeepEquals, logg.Fields{{"foo", "bar"}})
-	qt.Assert(t, c.Fields, qt.DeepEquals, logg.Fields{{"foo", "hello"}, {"bar", "world"}})
-	qt.Assert(t, d.Fields, qt.DeepEquals, logg.Fields{{"foo", "hello"}, {"bar", "world"}, {"baz", "jazz"}})
+	c := a.WithFields(logg.Fields{{Name: "foo", Value: "hello"}, {Name: "bar", Value: "world"}})
+	d := c.WithFields(logg.Fields{{Name: "baz", Value: "jazz"}})
+	qt.Assert(t, b.Fields, qt.DeepEquals, logg.Fields{{Name: "foo", Value: "bar"}})
+	qt.Assert(t, c.Fields, qt.DeepEquals, logg.Fields{{Name: "foo", Value: "hello"}, {Name: "bar", Value: "world"}})
+	qt.Assert(t, d.Fields, qt.DeepEquals, logg.Fields{{Name: "foo", Value: "hello"}, {Name: "bar", Value: "world"}, {Name: "baz", Value: "jazz"}})
 
 	c.Log(logg.String("upload"))
 	e := h.Entries[0]
 
 	qt.Assert(t, "upload", qt.Equals, e.Message)
-	qt.Assert(t, logg.Fields{{"foo", "hello"}, {"bar", "world"}}, qt.DeepEquals, e.Fields)
+	qt.Assert(t, logg.Fields{{Name: "foo", Value: "hello"}, {Name: "bar", Value: "world"}}, qt.DeepEquals, e.Fields)
 	qt.Assert(t, logg.LevelInfo, qt.Equals, e.Level)
 	qt.Assert(t, time.Now().IsZero(), qt.IsFalse)
 }
@@ -36,34 +39,82 @@ func TestEntry_WithManyFieldsWithSameName(t *testing.T) {
 	h := memory.New()
 	a := logg.New(logg.Options{Handler: h, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
 
-	b := a.WithFields(logg.Fields{{"foo", "bar"}})
+	b := a.WithFields(logg.Fields{{Name: "foo", Value: "bar"}})
 
 	for range 100 {
-		b = b.WithFields(logg.Fields{{"foo", "bar"}})
+		b = b.WithFields(logg.Fields{{Name: "foo", Value: "bar"}})
 	}
 
 	b.Log(logg.String("upload"))
 	e := h.Entries[0]
 
 	qt.Assert(t, "upload", qt.Equals, e.Message)
-	qt.Assert(t, logg.Fields{{"foo", "bar"}}, qt.DeepEquals, e.Fields)
+	qt.Assert(t, logg.Fields{{Name: "foo", Value: "bar"}}, qt.DeepEquals, e.Fields)
 
 }
 
+func TestEntry_WithGroup(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Handler: h, Level: logg.LevelInfo})
+	info := l.WithLevel(logg.LevelInfo)
+
+	http := info.WithField("user", "tj").WithGroup("http")
+	http.WithField("method", "GET").WithFields(logg.Fields{logg.Int("status", 500)}).WithField("status", 200).
+		WithGroup("res").WithField("size", 10).Log(logg.String("hello"))
+	http.Log(logg.String("no http fields"))
+	info.WithGroup("").WithField("a", 1).Log(logg.String("empty name"))
+	l.WithLevel(logg.LevelDebug).WithGroup("http").WithField("method", "GET").Log(logg.String("disabled"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 3)
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{
+		{Name: "user", Value: "tj"},
+		logg.Group("http",
+			logg.Field{Name: "method", Value: "GET"},
+			logg.Field{Name: "status", Value: 200},
+			logg.Group("res", logg.Field{Name: "size", Value: 10}),
+		),
+	})
+	qt.Assert(t, h.Entries[1].Fields, qt.DeepEquals, logg.Fields{{Name: "user", Value: "tj"}})
+	qt.Assert(t, h.Entries[2].Fields, qt.DeepEquals, logg.Fields{{Name: "a", Value: 1}})
+}
+
+func TestEntry_WithFields_groups(t *testing.T) {
+	h := memory.New()
+	info := logg.New(logg.Options{Handler: h, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
+
+	db := logg.Group("db", logg.Int("rows", 1), logg.Int("rows", 2))
+	info.WithFields(logg.Fields{
+		logg.Group("http", logg.Str("method", "GET")),
+		db,
+		logg.Group("http", logg.Int("status", 200), logg.Str("method", "POST")),
+		logg.Group("cache", logg.Bool("hit", true)),
+		logg.Str("cache", "replaced"),
+		logg.Group("cache", logg.Bool("hit", false)),
+	}).Log(logg.String("hello"))
+
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{
+		logg.Group("db", logg.Int("rows", 2)),
+		logg.Group("http", logg.Int("status", 200), logg.Str("method", "POST")),
+		logg.Group("cache", logg.Bool("hit", false)),
+	})
+	// The original group is not modified.
+	qt.Assert(t, db.Group(), qt.HasLen, 2)
+}
+
 func TestEntry_WithField(t *testing.T) {
 	h := memory.New()
 	a := logg.New(logg.Options{Handler: h, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
 	b := a.WithField("foo", "baz").WithField("foo", "bar")
 	b.Log(logg.String("upload"))
 	qt.Assert(t, a.Fields, qt.IsNil)
-	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{{"foo", "bar"}})
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{{Name: "foo", Value: "bar"}})
 }
 
 func TestEntry_WithError(t *testing.T) {
 	a := logg.New(logg.Options{Handler: handlers.Discard, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
 	b := a.WithError(fmt.Errorf("boom"))
 	qt.Assert(t, a.Fields, qt.IsNil)
-	qt.Assert(t, b.Fields, qt.DeepEquals, logg.Fields{{"error", "boom"}})
+	qt.Assert(t, b.Fields, qt.DeepEquals, logg.Fields{{Name: "error", Value: "boom"}})
 }
 
 func TestEntry_WithError_fields(t *testing.T) {
@@ -73,11 +124,36 @@ func TestEntry_WithError_fields(t *testing.T) {
 	qt.Assert(t,
 
 		b.Fields, qt.DeepEquals, logg.Fields{
-			{"error", "boom"},
-			{"reason", "timeout"},
+			{Name: "error", Value: "boom"},
+			{Name: "reason", Value: "timeout"},
 		})
 }
 
+func TestEntry_WithError_stack(t *testing.T) {
+	a := logg.New(logg.Options{Handler: handlers.Discard, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
+
+	qt.Assert(t, a.WithError(fmt.Errorf("boom")).Stack, qt.IsNil)
+
+	err := newStackError()
+	b := a.WithError(fmt.Errorf("wrapped: %w", err))
+	qt.Assert(t, b.Fields, qt.DeepEquals, logg.Fields{{Name: "error", Value: "wrapped: boom"}})
+	qt.Assert(t, len(b.Stack) > 1, qt.IsTrue)
+	qt.Assert(t, b.Stack[0].Function, qt.Equals, "github.com/bep/logg_test.newStackError")
+	qt.Assert(t, b.Stack[1].Function, qt.Equals, "github.com/bep/logg_test.TestEntry_WithError_stack")
+
+	// The deepest stack wins.
+	c := a.WithError(errors.Join(fmt.Errorf("no stack"), stackError{pcs: []uintptr{0}, err: err}))
+	qt.Assert(t, c.Stack[0].Function, qt.Equals, "github.com/bep/logg_test.newStackError")
+}
+
+func TestEntry_WithError_pkgErrors(t *testing.T) {
+	a := logg.New(logg.Options{Handler: handlers.Discard, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
+	err := fmt.Errorf("wrapped: %w", newPkgError())
+	b := a.WithError(err)
+	qt.Assert(t, b.Stack[0].Function, qt.Equals, "github.com/bep/logg_test.newPkgError")
+	qt.Assert(t, filepath.Base(b.Stack[0].File), qt.Equals, "entry_test.go")
+}
+
 func TestEntry_WithError_nil(t *testing.T) {
 	a := logg.New(logg.Options{Handler: handlers.Discard, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
 	b := a.WithError(nil)
@@ -88,7 +164,57 @@ func TestEntry_WithError_nil(t *testing.T) {
 func TestEntry_WithDuration(t *testing.T) {
 	a := logg.New(logg.Options{Handler: handlers.Discard, Level: logg.LevelInfo}).WithLevel(logg.LevelInfo)
 	b := a.WithDuration(time.Second * 2)
-	qt.Assert(t, b.Fields, qt.DeepEquals, logg.Fields{{"duration", int64(2000)}})
+	qt.Assert(t, b.Fields, qt.DeepEquals, logg.Fields{{Name: "duration", Value: int64(2000)}})
+}
+
+func TestEntry_Clone(t *testing.T) {
+	e := &logg.Entry{
+		Message: "hello",
+		Fields:  logg.Fields{logg.Str("a", "1"), logg.Group("http", logg.Int("status", 200), logg.Group("res", logg.Int("size", 10)))},
+	}
+	x := e.Clone()
+	qt.Assert(t, x.Fields, qt.DeepEquals, e.Fields)
+
+	x.Fields[0] = logg.Str("a", "2")
+	x.Fields[1].Group()[0] = logg.Int("status", 500)
+	x.Fields[1].Group()[1].Group()[0] = logg.Int("size", 20)
+
+	qt.Assert(t, e.Fields, qt.DeepEquals, logg.Fields{logg.Str("a", "1"), logg.Group("http", logg.Int("status", 200), logg.Group("res", logg.Int("size", 10)))})
+}
+
+type stackError struct {
+	pcs []uintptr
+	err error
+}
+
+func (e stackError) Error() string      { return e.err.Error() }
+func (e stackError) Unwrap() error      { return e.err }
+func (e stackError) Callers() []uintptr { return e.pcs }
+
+func newStackError() error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	return stackError{pcs: pcs[:n], err: errors.New("boom")}
+}
+
+// pkgError mimics the errors in github.com/pkg/errors.
+type (
+	pkgFrame      uintptr
+	pkgStackTrace []pkgFrame
+	pkgError      struct{ st pkgStackTrace }
+)
+
+func (e pkgError) Error() string             { return "boom" }
+func (e pkgError) StackTrace() pkgStackTrace { return e.st }
+
+func newPkgError() error {
+	var pcs [32]uintptr
+	n := runtime.Callers(1, pcs[:])
+	st := make(pkgStackTrace, n)
+	for i, pc := range pcs[:n] {
+		st[i] = pkgFrame(pc)
+	}
+	return pkgError{st: st}
 }
 
 type errFields string
@@ -98,5 +224,5 @@ func (ef errFields) Error() string {
 }
 
 func (ef errFields) Fields() logg.Fields {
-	return logg.Fields{{"reason", "timeout"}}
+	return logg.Fields{{Name: "reason", Value: "timeout"}}
 }
@@ -59,7 +59,7 @@ func Example_lazy_evaluation() {
 				// This func will never be invoked with the current logger's level.
 				func() logg.Fields {
 					return logg.Fields{
-						{"field", strings.Repeat("x", 9999)},
+						{Name: "field", Value: strings.Repeat("x", 9999)},
 					}
 
 				}),
@@ -0,0 +1,402 @@
+package logg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the kind of a Field's value.
+type Kind int
+
+// Field value kinds.
+// Fields created with a struct literal or with Any have KindAny.
+const (
+	KindAny Kind = iota
+	KindString
+	KindInt64
+	KindFloat64
+	KindBool
+	KindDuration
+	KindTime
+	KindGroup
+)
+
+// Time zones stored in Field.str for KindTime.
+const (
+	timeUTC   = ""
+	timeLocal = "L"
+)
+
+// Group returns a Field holding fields, e.g.
+//
+//	logg.Group("http", logg.Str("method", "GET"), logg.Int("status", 200))
+//
+// The json handler renders groups as nested objects, and the text
+// and cli handlers with dot separated names, e.g. "http.method".
+func Group(name string, fields ...Field) Field {
+	return Field{Name: name, Value: Fields(fields), kind: KindGroup}
+}
+
+// Lookup returns the last field in f with the given name.
+// Fields in groups are looked up with dot separated names, e.g.
+// "http.status" for the field status in the group http, the same names
+// as the text and cli handlers print.
+func (f Fields) Lookup(name string) (Field, bool) {
+	for i := len(f) - 1; i >= 0; i-- {
+		field := f[i]
+		if field.Name == name {
+			return field, true
+		}
+		if field.kind == KindGroup && len(name) > len(field.Name) && name[len(field.Name)] == '.' && strings.HasPrefix(name, field.Name) {
+			if g, found := field.Group().Lookup(name[len(field.Name)+1:]); found {
+				return g, true
+			}
+		}
+	}
+	return Field{}, false
+}
+
+// Valuer is implemented by values that are expensive to create, e.g.
+// a summary of a large struct. LogValue is called when the entry is
+// logged at an enabled level, and the handlers see the value it returns.
+//
+// If LogValue returns a Valuer, that is resolved as well, up to a limit.
+// If LogValue panics, the value is replaced by an error.
+type Valuer interface {
+	LogValue() any
+}
+
+// maxLogValueDepth is the maximum number of Valuers resolved for one value.
+const maxLogValueDepth = 100
+
+// resolveValue calls v.LogValue until the value is not a Valuer.
+func resolveValue(v Valuer) (value any) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = fmt.Errorf("LogValue panicked: %v", r)
+		}
+	}()
+
+	for range maxLogValueDepth {
+		value = v.LogValue()
+		var ok bool
+		if v, ok = value.(Valuer); !ok {
+			return value
+		}
+	}
+
+	return fmt.Errorf("LogValue called too many times on a value of type %T", v)
+}
+
+// resolveValues resolves the Valuer values in f in place,
+// including the values in groups.
+func (f Fields) resolveValues() {
+	for i, field := range f {
+		if field.kind == KindGroup {
+			field.Group().resolveValues()
+			continue
+		}
+		if v, ok := field.Value.(Valuer); ok {
+			f[i].Value = resolveValue(v)
+		}
+	}
+}
+
+// clone returns a copy of f with copies of the fields in groups.
+func (f Fields) clone() Fields {
+	c := make(Fields, len(f))
+	copy(c, f)
+	for i, field := range c {
+		if field.kind == KindGroup {
+			c[i].Value = field.Group().clone()
+		}
+	}
+	return c
+}
+
+// Str returns a Field with a string value.
+func Str(name, v string) Field {
+	return Field{Name: name, kind: KindString, str: v}
+}
+
+// Int64 returns a Field with an int64 value.
+func Int64(name string, v int64) Field {
+	return Field{Name: name, kind: KindInt64, num: uint64(v)}
+}
+
+// Int returns a Field with an int value stored as an int64.
+func Int(name string, v int) Field {
+	return Int64(name, int64(v))
+}
+
+// Float64 returns a Field with a float64 value.
+func Float64(name string, v float64) Field {
+	return Field{Name: name, kind: KindFloat64, num: math.Float64bits(v)}
+}
+
+// Bool returns a Field with a bool value.
+func Bool(name string, v bool) Field {
+	var u uint64
+	if v {
+		u = 1
+	}
+	return Field{Name: name, kind: KindBool, num: u}
+}
+
+// Duration returns a Field with a time.Duration value.
+func Duration(name string, v time.Duration) Field {
+	return Field{Name: name, kind: KindDuration, num: uint64(v)}
+}
+
+// Time returns a Field with a time.Time value.
+// Times in other locations than UTC and Local are stored as KindAny.
+// The monotonic clock reading is stripped.
+func Time(name string, v time.Time) Field {
+	var loc string
+	switch v.Location() {
+	case time.UTC:
+		loc = timeUTC
+	case time.Local:
+		loc = timeLocal
+	default:
+		return Any(name, v)
+	}
+	return Field{Name: name, kind: KindTime, num: uint64(v.UnixNano()), str: loc}
+}
+
+// Err returns a Field named "error" with err as its value.
+func Err(err error) Field {
+	return Field{Name: "error", Value: err}
+}
+
+// Any returns a Field with the value v.
+// This is the same as Field{Name: name, Value: v}.
+func Any(name string, v any) Field {
+	return Field{Name: name, Value: v}
+}
+
+// Kind returns f's Kind.
+func (f Field) Kind() Kind {
+	return f.kind
+}
+
+// Any returns f's value as an any.
+// Note that this allocates for most typed values, see Kind.
+func (f Field) Any() any {
+	switch f.kind {
+	case KindString:
+		return f.str
+	case KindInt64:
+		return f.Int64()
+	case KindFloat64:
+		return f.Float64()
+	case KindBool:
+		return f.Bool()
+	case KindDuration:
+		return f.Duration()
+	case KindTime:
+		return f.Time()
+	default:
+		return f.Value
+	}
+}
+
+// Str returns f's value if f is KindString, else the empty string.
+func (f Field) Str() string {
+	if f.kind != KindString {
+		return ""
+	}
+	return f.str
+}
+
+// Int64 returns f's value if f is KindInt64, else 0.
+func (f Field) Int64() int64 {
+	if f.kind != KindInt64 {
+		return 0
+	}
+	return int64(f.num)
+}
+
+// Float64 returns f's value if f is KindFloat64, else 0.
+func (f Field) Float64() float64 {
+	if f.kind != KindFloat64 {
+		return 0
+	}
+	return math.Float64frombits(f.num)
+}
+
+// Bool returns f's value if f is KindBool, else false.
+func (f Field) Bool() bool {
+	return f.kind == KindBool && f.num == 1
+}
+
+// Duration returns f's value if f is KindDuration, else 0.
+func (f Field) Duration() time.Duration {
+	if f.kind != KindDuration {
+		return 0
+	}
+	return time.Duration(f.num)
+}
+
+// Time returns f's value if f is KindTime, else the zero time.
+func (f Field) Time() time.Time {
+	if f.kind != KindTime {
+		return time.Time{}
+	}
+	t := time.Unix(0, int64(f.num))
+	if f.str == timeUTC {
+		return t.UTC()
+	}
+	return t
+}
+
+// Group returns the fields of a group, or nil if f is not of KindGroup.
+func (f Field) Group() Fields {
+	if f.kind != KindGroup {
+		return nil
+	}
+	return f.Value.(Fields)
+}
+
+// Equal reports whether f and g have the same name and value.
+// Typed values are equal to the same value of KindAny, e.g.
+// Int64("a", 1) is equal to Any("a", int64(1)).
+func (f Field) Equal(g Field) bool {
+	if f.Name != g.Name {
+		return false
+	}
+	if f.kind == KindGroup || g.kind == KindGroup {
+		fg, gg := f.Group(), g.Group()
+		if f.kind != g.kind || len(fg) != len(gg) {
+			return false
+		}
+		for i := range fg {
+			if !fg[i].Equal(gg[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if f.kind == g.kind && f.kind != KindAny {
+		return f.num == g.num && f.str == g.str
+	}
+	return reflect.DeepEqual(f.Any(), g.Any())
+}
+
+// AppendValue appends the text representation of f's value to dst.
+// Typed values are formatted without reflection, values of KindAny
+// are formatted with fmt's %v verb.
+// Durations are formatted with time.Duration.String and times in RFC 3339 format.
+// Groups are formatted as {name=value name=value}.
+func (f Field) AppendValue(dst []byte) []byte {
+	switch f.kind {
+	case KindGroup:
+		dst = append(dst, '{')
+		for i, g := range f.Group() {
+			if i > 0 {
+				dst = append(dst, ' ')
+			}
+			dst = append(dst, g.Name...)
+			dst = append(dst, '=')
+			dst = g.AppendValue(dst)
+		}
+		return append(dst, '}')
+	case KindString:
+		return append(dst, f.str...)
+	case KindInt64:
+		return strconv.AppendInt(dst, f.Int64(), 10)
+	case KindFloat64:
+		return strconv.AppendFloat(dst, f.Float64(), 'g', -1, 64)
+	case KindBool:
+		return strconv.AppendBool(dst, f.Bool())
+	case KindDuration:
+		return append(dst, f.Duration().String()...)
+	case KindTime:
+		return f.Time().AppendFormat(dst, time.RFC3339Nano)
+	default:
+		return appendAnyValue(dst, f.Value)
+	}
+}
+
+func appendAnyValue(dst []byte, v any) []byte {
+	switch v := v.(type) {
+	case string:
+		return append(dst, v...)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(dst, v, 10)
+	case bool:
+		return strconv.AppendBool(dst, v)
+	case error:
+		return append(dst, v.Error()...)
+	case SecretValue:
+		return append(dst, secretMask...)
+	default:
+		return fmt.Appendf(dst, "%v", v)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+// Groups are marshaled as objects.
+func (f Field) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, struct {
+		Name  string `json:"name"`
+		Value any    `json:"value"`
+	}{f.Name, f.jsonValue()}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (f Field) jsonValue() any {
+	if f.kind == KindGroup {
+		return jsonGroup(f.Group())
+	}
+	v := f.Any()
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
+
+// jsonGroup marshals the fields in a group as a JSON object.
+type jsonGroup Fields
+
+func (g jsonGroup) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range g {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := encodeJSON(&buf, f.Name); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := encodeJSON(&buf, f.jsonValue()); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// encodeJSON writes v to buf as JSON without HTML escaping.
+func encodeJSON(buf *bytes.Buffer, v any) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	// Remove the newline added by Encode.
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
@@ -0,0 +1,182 @@
+package logg_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/json"
+	"github.com/bep/logg/handlers/memory"
+	"github.com/bep/logg/handlers/multi"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestField(t *testing.T) {
+	c := qt.New(t)
+	tm := time.Date(2022, 8, 12, 10, 0, 0, 42, time.UTC)
+	err := errFields("boom")
+
+	for _, test := range []struct {
+		field logg.Field
+		kind  logg.Kind
+		any   any
+		text  string
+	}{
+		{logg.Str("a", "v"), logg.KindString, "v", "v"},
+		{logg.Int64("a", -32), logg.KindInt64, int64(-32), "-32"},
+		{logg.Int("a", 32), logg.KindInt64, int64(32), "32"},
+		{logg.Float64("a", 1.5), logg.KindFloat64, 1.5, "1.5"},
+		{logg.Float64("a", math.Inf(1)), logg.KindFloat64, math.Inf(1), "+Inf"},
+		{logg.Bool("a", true), logg.KindBool, true, "true"},
+		{logg.Bool("a", false), logg.KindBool, false, "false"},
+		{logg.Duration("a", 1500*time.Millisecond), logg.KindDuration, 1500 * time.Millisecond, "1.5s"},
+		{logg.Time("a", tm), logg.KindTime, tm, "2022-08-12T10:00:00.000000042Z"},
+		{logg.Err(err), logg.KindAny, err, "boom"},
+		{logg.Any("a", []int{1, 2}), logg.KindAny, []int{1, 2}, "[1 2]"},
+		{logg.Field{Name: "a", Value: 32}, logg.KindAny, 32, "32"},
+	} {
+		c.Assert(test.field.Kind(), qt.Equals, test.kind)
+		c.Assert(test.field.Any(), qt.DeepEquals, test.any)
+		c.Assert(string(test.field.AppendValue(nil)), qt.Equals, test.text)
+		c.Assert(test.field, qt.DeepEquals, logg.Field{Name: test.field.Name, Value: test.any})
+	}
+}
+
+func TestFieldTimeLocal(t *testing.T) {
+	tm := time.Now()
+	f := logg.Time("a", tm)
+	qt.Assert(t, f.Kind(), qt.Equals, logg.KindTime)
+	qt.Assert(t, f.Time().Equal(tm), qt.IsTrue)
+	qt.Assert(t, f.Time().Location(), qt.Equals, time.Local)
+
+	loc := time.FixedZone("X", 3600)
+	f = logg.Time("a", tm.In(loc))
+	qt.Assert(t, f.Kind(), qt.Equals, logg.KindAny)
+	qt.Assert(t, f.Any(), qt.Equals, tm.In(loc))
+}
+
+func TestFieldAccessorsWrongKind(t *testing.T) {
+	f := logg.Str("a", "v")
+	qt.Assert(t, f.Int64(), qt.Equals, int64(0))
+	qt.Assert(t, f.Bool(), qt.IsFalse)
+	qt.Assert(t, logg.Int64("a", 1).Str(), qt.Equals, "")
+}
+
+func TestFieldEqual(t *testing.T) {
+	qt.Assert(t, logg.Int64("a", 1).Equal(logg.Int64("a", 1)), qt.IsTrue)
+	qt.Assert(t, logg.Int64("a", 1).Equal(logg.Int64("b", 1)), qt.IsFalse)
+	qt.Assert(t, logg.Int64("a", 1).Equal(logg.Int64("a", 2)), qt.IsFalse)
+	qt.Assert(t, logg.Int64("a", 1).Equal(logg.Any("a", int64(1))), qt.IsTrue)
+	qt.Assert(t, logg.Int64("a", 1).Equal(logg.Any("a", 1)), qt.IsFalse)
+}
+
+func TestFieldMarshalJSON(t *testing.T) {
+	b, err := logg.Err(errors.New("boom")).MarshalJSON()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, string(b), qt.Equals, `{"name":"error","value":"boom"}`)
+	b, err = logg.Duration("d", time.Second).MarshalJSON()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, string(b), qt.Equals, `{"name":"d","value":1000000000}`)
+}
+
+type valuerFunc func() any
+
+func (f valuerFunc) LogValue() any {
+	return f()
+}
+
+func TestFieldValuer(t *testing.T) {
+	var calls int
+	expensive := valuerFunc(func() any {
+		calls++
+		return "summary"
+	})
+
+	var buf bytes.Buffer
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: multi.New(h, json.New(&buf))})
+
+	l.WithLevel(logg.LevelDebug).WithField("data", expensive).Log(logg.String("disabled"))
+	qt.Assert(t, calls, qt.Equals, 0)
+
+	l.WithLevel(logg.LevelInfo).WithField("data", expensive).Log(logg.String("enabled"))
+	qt.Assert(t, calls, qt.Equals, 1)
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{{Name: "data", Value: "summary"}})
+	qt.Assert(t, buf.String(), qt.Contains, `"fields":[{"name":"data","value":"summary"}]`)
+
+	// The entry's own fields are not modified.
+	info := l.WithLevel(logg.LevelInfo).WithField("data", expensive)
+	info.Log(logg.String("again"))
+	_, ok := info.Fields[0].Value.(logg.Valuer)
+	qt.Assert(t, ok, qt.IsTrue)
+}
+
+func TestFieldValuerNested(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	nested := valuerFunc(func() any { return valuerFunc(func() any { return 42 }) })
+	var loop valuerFunc
+	loop = func() any { return loop }
+	panics := valuerFunc(func() any { panic("boom") })
+
+	l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{
+		{Name: "nested", Value: nested},
+		{Name: "loop", Value: loop},
+		{Name: "panics", Value: panics},
+	}).Log(logg.String("hello"))
+
+	fields := h.Entries[0].Fields
+	qt.Assert(t, fields[0].Value, qt.Equals, 42)
+	qt.Assert(t, fields[1].Value, qt.ErrorMatches, "LogValue called too many times on a value of type logg_test.valuerFunc")
+	qt.Assert(t, fields[2].Value, qt.ErrorMatches, "LogValue panicked: boom")
+}
+
+func TestFieldGroup(t *testing.T) {
+	g := logg.Group("http", logg.Str("method", "GET"), logg.Group("res", logg.Int("status", 200)))
+
+	qt.Assert(t, g.Kind(), qt.Equals, logg.KindGroup)
+	qt.Assert(t, g.Group(), qt.HasLen, 2)
+	qt.Assert(t, logg.Str("a", "b").Group(), qt.IsNil)
+	qt.Assert(t, string(g.AppendValue(nil)), qt.Equals, "{method=GET res={status=200}}")
+
+	b, err := g.MarshalJSON()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, string(b), qt.Equals, `{"name":"http","value":{"method":"GET","res":{"status":200}}}`)
+
+	qt.Assert(t, g.Equal(logg.Group("http", logg.Any("method", "GET"), logg.Group("res", logg.Int64("status", 200)))), qt.IsTrue)
+	qt.Assert(t, g.Equal(logg.Group("http", logg.Str("method", "GET"))), qt.IsFalse)
+	qt.Assert(t, g.Equal(logg.Group("http", logg.Str("method", "GET"), logg.Group("res", logg.Int("status", 500)))), qt.IsFalse)
+	qt.Assert(t, g.Equal(logg.Any("http", g.Group())), qt.IsFalse)
+}
+
+func TestFieldsLookup(t *testing.T) {
+	fields := logg.Fields{
+		logg.Int("status", 404),
+		logg.Group("http", logg.Str("method", "GET"), logg.Group("res", logg.Int("status", 503))),
+		logg.Str("http.method", "POST"),
+		logg.Int("status", 200),
+	}
+
+	for _, test := range []struct {
+		name     string
+		expected logg.Field
+		found    bool
+	}{
+		{"status", logg.Int("status", 200), true},
+		{"http.res.status", logg.Int("status", 503), true},
+		{"http.res", fields[1].Group()[1], true},
+		{"http.method", logg.Str("http.method", "POST"), true},
+		{"http.status", logg.Field{}, false},
+		{"res.status", logg.Field{}, false},
+		{"http.", logg.Field{}, false},
+		{"httpx.method", logg.Field{}, false},
+	} {
+		f, found := fields.Lookup(test.name)
+		qt.Assert(t, found, qt.Equals, test.found, qt.Commentf(test.name))
+		qt.Assert(t, f.Equal(test.expected), qt.IsTrue, qt.Commentf(test.name))
+	}
+}
@@ -7,7 +7,6 @@ require (
 	github.com/fatih/color v1.18.0
 	github.com/frankban/quicktest v1.14.6
 	github.com/mattn/go-colorable v0.1.14
-	github.com/pkg/errors v0.9.1
 )
 
 require (
@@ -1,5 +1,10 @@
 package logg
 
+import (
+	"context"
+	"errors"
+)
+
 // Handler is used to handle log events, outputting them to
 // stdio or sending them to remote services. See the "handlers"
 // directory for implementations.
@@ -16,6 +21,81 @@ type Handler interface {
 	HandleLog(e *Entry) error
 }
 
+// ContextHandler is a Handler that also accepts a context.Context,
+// e.g. to extract request scoped values such as trace IDs.
+//
+// HandleLogContext is preferred over HandleLog when a logger or a
+// wrapping handler (e.g. multi.Handler) invokes a ContextHandler.
+type ContextHandler interface {
+	Handler
+
+	// HandleLogContext is invoked for each log event with the context
+	// set on the Entry with WithContext, or context.Background() if not set.
+	// The same rules as for HandleLog applies to e.
+	HandleLogContext(ctx context.Context, e *Entry) error
+}
+
+// HandleLogContext passes e to h, using HandleLogContext if h implements ContextHandler.
+// This is mostly useful for handlers wrapping other handlers.
+func HandleLogContext(ctx context.Context, h Handler, e *Entry) error {
+	if ch, ok := h.(ContextHandler); ok {
+		return ch.HandleLogContext(ctx, e)
+	}
+	return h.HandleLog(e)
+}
+
+// Flusher is a Handler that buffers entries, e.g. to write them in batches
+// or from a background goroutine.
+type Flusher interface {
+	// Flush passes on or writes any buffered entries and waits for them
+	// to be handled, or until ctx is done.
+	Flush(ctx context.Context) error
+}
+
+// Closer is a Handler that holds resources that need to be released,
+// e.g. a network connection or a goroutine.
+type Closer interface {
+	// Close flushes any buffered entries and releases the handler's resources.
+	// The handler must not be used after Close is called.
+	Close(ctx context.Context) error
+}
+
+// Flush flushes h if it implements Flusher.
+// This is mostly useful for handlers wrapping other handlers.
+func Flush(ctx context.Context, h Handler) error {
+	if f, ok := h.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close closes h if it implements Closer, or else flushes it if it implements Flusher.
+// This is mostly useful for handlers wrapping other handlers.
+func Close(ctx context.Context, h Handler) error {
+	if c, ok := h.(Closer); ok {
+		return c.Close(ctx)
+	}
+	return Flush(ctx, h)
+}
+
+// FlushAll flushes all handlers in hs and returns the errors joined.
+func FlushAll(ctx context.Context, hs ...Handler) error {
+	var errs []error
+	for _, h := range hs {
+		errs = append(errs, Flush(ctx, h))
+	}
+	return errors.Join(errs...)
+}
+
+// CloseAll closes all handlers in hs and returns the errors joined.
+func CloseAll(ctx context.Context, hs ...Handler) error {
+	var errs []error
+	for _, h := range hs {
+		errs = append(errs, Close(ctx, h))
+	}
+	return errors.Join(errs...)
+}
+
 // The HandlerFunc type is an adapter to allow the use of ordinary functions as
 // log handlers. If f is a function with the appropriate signature,
 // HandlerFunc(f) is a Handler object that calls f.
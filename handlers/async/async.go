@@ -0,0 +1,286 @@
+// Package async implements a handler which passes log entries on to
+// another handler from a background goroutine via a bounded queue.
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// ErrClosed is returned when logging to a closed Handler.
+var ErrClosed = errors.New("async: handler is closed")
+
+// Policy decides what to do when the queue is full.
+type Policy int
+
+const (
+	// Block waits until there's room in the queue.
+	Block Policy = iota
+
+	// DropNewest drops the entry being logged.
+	DropNewest
+
+	// DropOldest drops the oldest entry in the queue.
+	DropOldest
+
+	// DropLowerLevels drops the oldest of the queued entries with the
+	// lowest level, if lower than the level of the entry being logged.
+	// If not, the entry being logged is dropped.
+	DropLowerLevels
+)
+
+// Options holds options for the async handler.
+type Options struct {
+	// QueueSize is the maximum number of entries waiting to be handled.
+	// Default is 1024.
+	QueueSize int
+
+	// Overflow decides what to do when the queue is full.
+	// Default is Block.
+	Overflow Policy
+
+	// OnError, if set, is called with any error returned from the
+	// wrapped handler, except logg.ErrStopLogEntry.
+	OnError func(e *logg.Entry, err error)
+}
+
+// Handler implementation.
+//
+// Entries are cloned and queued, and passed on to the wrapped handler in
+// order by a background goroutine.
+type Handler struct {
+	opts    Options
+	handler logg.Handler
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	changed  *sync.Cond // Signaled when an entry is removed from the queue.
+	queue    []item
+	head     int
+	n        int
+	closed   bool
+
+	// Flush waits for finished to catch up with the number of entries
+	// queued when it was called; entries are finished when they're
+	// handled or dropped from the queue.
+	queued   uint64
+	finished uint64
+	progress chan struct{} // Closed and cleared when finished is incremented.
+
+	dropped uint64
+	done    chan struct{}
+}
+
+// New handler passing entries to h from a background goroutine.
+// Close must be called to stop the goroutine.
+func New(h logg.Handler, opts Options) *Handler {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	a := &Handler{
+		opts:    opts,
+		handler: h,
+		queue:   make([]item, opts.QueueSize),
+		done:    make(chan struct{}),
+	}
+	a.notEmpty = sync.NewCond(&a.mu)
+	a.changed = sync.NewCond(&a.mu)
+	go a.run()
+	return a
+}
+
+type item struct {
+	ctx context.Context
+	e   *logg.Entry
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+// The entry is cloned and queued to be handled by the wrapped handler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for !h.closed && h.n == len(h.queue) {
+		switch h.opts.Overflow {
+		case DropNewest:
+			h.dropped++
+			return nil
+		case DropOldest:
+			h.removeLocked(h.head)
+			h.finishLocked()
+			h.dropped++
+		case DropLowerLevels:
+			i := h.lowestLevelLocked()
+			if h.queue[i].e.Level >= e.Level {
+				h.dropped++
+				return nil
+			}
+			h.removeLocked(i)
+			h.finishLocked()
+			h.dropped++
+		default:
+			h.changed.Wait()
+		}
+	}
+
+	if h.closed {
+		return ErrClosed
+	}
+
+	h.queue[(h.head+h.n)%len(h.queue)] = item{ctx: ctx, e: e.Clone()}
+	h.n++
+	h.queued++
+	h.notEmpty.Signal()
+
+	return nil
+}
+
+// Len returns the number of entries waiting in the queue.
+func (h *Handler) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.n
+}
+
+// Dropped returns the number of entries dropped because the queue was full.
+func (h *Handler) Dropped() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.dropped
+}
+
+// Flush implements logg.Flusher.
+// It waits until the entries queued before the call are handled and then
+// flushes the wrapped handler, or until ctx is done.
+func (h *Handler) Flush(ctx context.Context) error {
+	if err := h.wait(ctx); err != nil {
+		return err
+	}
+	return logg.Flush(ctx, h.handler)
+}
+
+// Close implements logg.Closer.
+// It handles the queued entries, stops the background goroutine and
+// then closes the wrapped handler.
+// Entries logged after Close is called are not handled.
+// If ctx is done before the queue is drained, ctx.Err() is returned and
+// the remaining entries are handled in the background, but the wrapped
+// handler is not closed.
+func (h *Handler) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.notEmpty.Broadcast()
+	h.changed.Broadcast()
+	h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		return logg.Close(ctx, h.handler)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wait waits until the entries queued so far are finished, or until ctx is done.
+func (h *Handler) wait(ctx context.Context) error {
+	h.mu.Lock()
+	target := h.queued
+	for h.finished < target {
+		if h.progress == nil {
+			h.progress = make(chan struct{})
+		}
+		progress := h.progress
+		h.mu.Unlock()
+
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		h.mu.Lock()
+	}
+	h.mu.Unlock()
+	return nil
+}
+
+// finishLocked marks an entry as handled or dropped and wakes up any
+// waiting Flush calls.
+func (h *Handler) finishLocked() {
+	h.finished++
+	if h.progress != nil {
+		close(h.progress)
+		h.progress = nil
+	}
+}
+
+func (h *Handler) run() {
+	defer close(h.done)
+	for {
+		h.mu.Lock()
+		for h.n == 0 && !h.closed {
+			h.notEmpty.Wait()
+		}
+		if h.n == 0 {
+			h.mu.Unlock()
+			return
+		}
+		it := h.queue[h.head]
+		h.removeLocked(h.head)
+		h.mu.Unlock()
+
+		if err := logg.HandleLogContext(it.ctx, h.handler, it.e); err != nil && err != logg.ErrStopLogEntry {
+			if h.opts.OnError != nil {
+				h.opts.OnError(it.e, err)
+			}
+		}
+
+		h.mu.Lock()
+		h.finishLocked()
+		h.mu.Unlock()
+	}
+}
+
+// removeLocked removes the entry at index i in the ring buffer,
+// keeping the order of the other entries.
+func (h *Handler) removeLocked(i int) {
+	size := len(h.queue)
+	// Shift the entries before i one step forward.
+	for j := i; j != h.head; {
+		prev := (j - 1 + size) % size
+		h.queue[j] = h.queue[prev]
+		j = prev
+	}
+	h.queue[h.head] = item{}
+	h.head = (h.head + 1) % size
+	h.n--
+	h.changed.Broadcast()
+}
+
+// lowestLevelLocked returns the index of the oldest entry with the lowest level.
+func (h *Handler) lowestLevelLocked() int {
+	size := len(h.queue)
+	lowest := h.head
+	for k := 1; k < h.n; k++ {
+		i := (h.head + k) % size
+		if h.queue[i].e.Level < h.queue[lowest].e.Level {
+			lowest = i
+		}
+	}
+	return lowest
+}
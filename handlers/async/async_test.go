@@ -0,0 +1,276 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/async"
+	"github.com/bep/logg/handlers/memory"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestAsync(t *testing.T) {
+	h := memory.New()
+	a := async.New(h, async.Options{})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+
+	for range 100 {
+		l.WithLevel(logg.LevelInfo).WithField("user", "tj").Log(logg.String("hello"))
+	}
+
+	qt.Assert(t, a.Flush(context.Background()), qt.IsNil)
+	qt.Assert(t, a.Len(), qt.Equals, 0)
+	qt.Assert(t, h.Entries, qt.HasLen, 100)
+	qt.Assert(t, h.Entries[99].Fields, qt.DeepEquals, logg.Fields{{Name: "user", Value: "tj"}})
+
+	qt.Assert(t, a.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, a.HandleLog(h.Entries[0]), qt.Equals, async.ErrClosed)
+}
+
+func TestAsyncFlushCloseWrapped(t *testing.T) {
+	h := &lifecycleHandler{}
+	a := async.New(h, async.Options{})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+
+	l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+	qt.Assert(t, l.Flush(context.Background()), qt.IsNil)
+	l.WithLevel(logg.LevelInfo).Log(logg.String("world"))
+	qt.Assert(t, l.Close(context.Background()), qt.IsNil)
+
+	qt.Assert(t, h.calls, qt.DeepEquals, []string{"hello", "flush", "world", "close"})
+}
+
+type lifecycleHandler struct {
+	calls []string
+}
+
+func (h *lifecycleHandler) HandleLog(e *logg.Entry) error {
+	h.calls = append(h.calls, e.Message)
+	return nil
+}
+
+func (h *lifecycleHandler) Flush(ctx context.Context) error {
+	h.calls = append(h.calls, "flush")
+	return nil
+}
+
+func (h *lifecycleHandler) Close(ctx context.Context) error {
+	h.calls = append(h.calls, "close")
+	return nil
+}
+
+type ctxKey string
+
+func TestAsyncContext(t *testing.T) {
+	var values []any
+	h := contextHandler(func(ctx context.Context, e *logg.Entry) error {
+		values = append(values, ctx.Value(ctxKey("trace")))
+		return nil
+	})
+	a := async.New(h, async.Options{})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t1")
+	l.WithLevel(logg.LevelInfo).WithContext(ctx).Log(logg.String("hello"))
+
+	qt.Assert(t, a.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, values, qt.DeepEquals, []any{"t1"})
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		policy   async.Policy
+		messages []string
+		dropped  uint64
+	}{
+		{"DropNewest", async.DropNewest, []string{"blocker", "d1", "i2", "w3"}, 3},
+		{"DropOldest", async.DropOldest, []string{"blocker", "d4", "e5", "i6"}, 3},
+		{"DropLowerLevels", async.DropLowerLevels, []string{"blocker", "w3", "e5", "e7"}, 4},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := newGatedHandler()
+			a := async.New(g, async.Options{QueueSize: 3, Overflow: test.policy})
+			l := logg.New(logg.Options{Level: logg.LevelTrace, Handler: a})
+
+			l.WithLevel(logg.LevelInfo).Log(logg.String("blocker"))
+			<-g.started
+
+			l.WithLevel(logg.LevelDebug).Log(logg.String("d1"))
+			l.WithLevel(logg.LevelInfo).Log(logg.String("i2"))
+			l.WithLevel(logg.LevelWarn).Log(logg.String("w3"))
+			l.WithLevel(logg.LevelDebug).Log(logg.String("d4"))
+			l.WithLevel(logg.LevelError).Log(logg.String("e5"))
+			l.WithLevel(logg.LevelInfo).Log(logg.String("i6"))
+			if test.policy == async.DropLowerLevels {
+				l.WithLevel(logg.LevelError).Log(logg.String("e7"))
+			}
+			qt.Assert(t, a.Len(), qt.Equals, 3)
+
+			close(g.release)
+			qt.Assert(t, a.Close(context.Background()), qt.IsNil)
+			qt.Assert(t, g.messages(), qt.DeepEquals, test.messages)
+			qt.Assert(t, a.Dropped(), qt.Equals, test.dropped)
+		})
+	}
+}
+
+func TestAsyncBlock(t *testing.T) {
+	g := newGatedHandler()
+	a := async.New(g, async.Options{QueueSize: 1})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+	info := l.WithLevel(logg.LevelInfo)
+
+	info.Log(logg.String("blocker"))
+	<-g.started
+	info.Log(logg.String("queued"))
+
+	done := make(chan struct{})
+	go func() {
+		info.Log(logg.String("blocked"))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected HandleLog to block")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(g.release)
+	<-done
+	qt.Assert(t, a.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, g.messages(), qt.DeepEquals, []string{"blocker", "queued", "blocked"})
+	qt.Assert(t, a.Dropped(), qt.Equals, uint64(0))
+}
+
+func TestAsyncFlushContext(t *testing.T) {
+	g := newGatedHandler()
+	a := async.New(g, async.Options{})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+
+	l.WithLevel(logg.LevelInfo).Log(logg.String("blocker"))
+	<-g.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	n := runtime.NumGoroutine()
+	for range 3 {
+		qt.Assert(t, a.Flush(ctx), qt.Equals, context.DeadlineExceeded)
+	}
+	// No goroutines are left waiting.
+	qt.Assert(t, runtime.NumGoroutine(), qt.Equals, n)
+	qt.Assert(t, a.Close(ctx), qt.Equals, context.DeadlineExceeded)
+
+	close(g.release)
+	qt.Assert(t, a.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, g.messages(), qt.DeepEquals, []string{"blocker"})
+}
+
+func TestAsyncFlushWhileLogging(t *testing.T) {
+	a := async.New(logg.HandlerFunc(func(e *logg.Entry) error { return nil }), async.Options{})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+			}
+		}
+	})
+
+	// Flush only waits for the entries queued before the call.
+	for range 10 {
+		qt.Assert(t, a.Flush(context.Background()), qt.IsNil)
+	}
+	close(stop)
+	wg.Wait()
+	qt.Assert(t, a.Close(context.Background()), qt.IsNil)
+}
+
+func TestAsyncOnError(t *testing.T) {
+	var errs []error
+	h := logg.HandlerFunc(func(e *logg.Entry) error {
+		if e.Message == "stop" {
+			return logg.ErrStopLogEntry
+		}
+		return errors.New(e.Message)
+	})
+	a := async.New(h, async.Options{OnError: func(e *logg.Entry, err error) { errs = append(errs, err) }})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+
+	l.WithLevel(logg.LevelInfo).Log(logg.String("stop"))
+	l.WithLevel(logg.LevelInfo).Log(logg.String("boom"))
+
+	qt.Assert(t, a.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, errs, qt.HasLen, 1)
+	qt.Assert(t, errs[0], qt.ErrorMatches, "boom")
+}
+
+func BenchmarkAsync(b *testing.B) {
+	a := async.New(logg.HandlerFunc(func(e *logg.Entry) error { return nil }), async.Options{})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: a})
+	info := l.WithLevel(logg.LevelInfo).WithField("user", "tj")
+
+	for b.Loop() {
+		info.Log(logg.String("hello"))
+	}
+
+	if err := a.Close(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// gatedHandler blocks in the first call to HandleLog until release is closed.
+type gatedHandler struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+
+	mu   sync.Mutex
+	msgs []string
+}
+
+func newGatedHandler() *gatedHandler {
+	return &gatedHandler{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (g *gatedHandler) HandleLog(e *logg.Entry) error {
+	g.once.Do(func() {
+		close(g.started)
+		<-g.release
+	})
+	g.mu.Lock()
+	g.msgs = append(g.msgs, e.Message)
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *gatedHandler) messages() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.msgs
+}
+
+type contextHandler func(ctx context.Context, e *logg.Entry) error
+
+func (h contextHandler) HandleLog(e *logg.Entry) error {
+	return h(e.Context(), e)
+}
+
+func (h contextHandler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	return h(ctx, e)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/bep/logg"
@@ -15,24 +16,62 @@ import (
 // Default handler outputting to stderr.
 var Default = New(os.Stderr)
 
-var bold = color.New(color.Bold)
+var (
+	bold  = color.New(color.Bold)
+	faint = color.New(color.Faint)
+)
+
+// Colors mapping, initialized with the colors of the registered levels,
+// see logg.LevelSpec.
+var Colors = make(map[logg.Level]*color.Color)
 
-// Colors mapping.
-var Colors = [...]*color.Color{
-	logg.LevelTrace: color.New(color.FgWhite),
-	logg.LevelDebug: color.New(color.FgWhite),
-	logg.LevelInfo:  color.New(color.FgBlue),
-	logg.LevelWarn:  color.New(color.FgYellow),
-	logg.LevelError: color.New(color.FgRed),
+// Strings mapping, initialized with the symbols of the registered levels,
+// see logg.LevelSpec.
+var Strings = make(map[logg.Level]string)
+
+func init() {
+	for _, spec := range logg.Levels() {
+		Colors[spec.Level] = levelColor(spec)
+		Strings[spec.Level] = levelSymbol(spec)
+	}
 }
 
-// Strings mapping.
-var Strings = [...]string{
-	logg.LevelTrace: "•",
-	logg.LevelDebug: "•",
-	logg.LevelInfo:  "•",
-	logg.LevelWarn:  "•",
-	logg.LevelError: "⨯",
+// levelStyle returns the color and symbol for level.
+// Levels registered after this package is initialized fall back to the
+// defaults in their logg.LevelSpec, and unregistered levels are printed
+// without color.
+func levelStyle(level logg.Level) (*color.Color, string) {
+	c, found := Colors[level]
+	s, sfound := Strings[level]
+	if found && sfound {
+		return c, s
+	}
+	spec, _ := logg.LookupLevel(level)
+	if !found {
+		c = levelColor(spec)
+	}
+	if !sfound {
+		s = levelSymbol(spec)
+	}
+	return c, s
+}
+
+func levelColor(spec logg.LevelSpec) *color.Color {
+	if len(spec.Color) == 0 {
+		return color.New(color.Reset)
+	}
+	attrs := make([]color.Attribute, len(spec.Color))
+	for i, a := range spec.Color {
+		attrs[i] = color.Attribute(a)
+	}
+	return color.New(attrs...)
+}
+
+func levelSymbol(spec logg.LevelSpec) string {
+	if spec.Symbol == "" {
+		return "•"
+	}
+	return spec.Symbol
 }
 
 // Handler implementation.
@@ -59,22 +98,40 @@ func New(w io.Writer) *Handler {
 
 // HandleLog implements logg.Handler.
 func (h *Handler) HandleLog(e *logg.Entry) error {
-	color := Colors[e.Level]
-	level := Strings[e.Level]
+	color, level := levelStyle(e.Level)
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	color.Fprintf(h.Writer, "%s %-25s", bold.Sprintf("%*s", h.Padding+1, level), e.Message)
+	color.Fprintf(h.Writer, "%s ", bold.Sprintf("%*s", h.Padding+1, level))
+	if e.LoggerName != "" {
+		color.Fprintf(h.Writer, "[%s] ", e.LoggerName)
+	}
+	color.Fprintf(h.Writer, "%-25s", e.Message)
 
-	for _, field := range e.Fields {
-		if field.Name == "source" {
-			continue
-		}
-		fmt.Fprintf(h.Writer, " %s=%v", color.Sprint(field.Name), field.Value)
+	h.printFields(color, "", e.Fields)
+
+	if e.Source.Line != 0 {
+		fmt.Fprintf(h.Writer, " %s", faint.Sprintf("(%s:%d)", filepath.Base(e.Source.File), e.Source.Line))
 	}
 
 	fmt.Fprintln(h.Writer)
 
+	for _, f := range e.Stack {
+		fmt.Fprintf(h.Writer, "%*s %s\n", h.Padding+1, "", faint.Sprintf("%s (%s)", f.Function, f))
+	}
+
 	return nil
 }
+
+// printFields prints fields with the fields in groups prefixed with the
+// dot separated group names.
+func (h *Handler) printFields(c *color.Color, prefix string, fields logg.Fields) {
+	for _, field := range fields {
+		if field.Kind() == logg.KindGroup {
+			h.printFields(c, prefix+field.Name+".", field.Group())
+			continue
+		}
+		fmt.Fprintf(h.Writer, " %s=%s", c.Sprint(prefix+field.Name), field.AppendValue(nil))
+	}
+}
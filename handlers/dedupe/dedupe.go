@@ -0,0 +1,161 @@
+// Package dedupe implements a handler which collapses consecutive
+// repeated log entries into one.
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// Options holds options for the dedupe handler.
+type Options struct {
+	// FlushTimeout is how long to wait for another repeat before the
+	// held back entries are flushed. Repeats arriving later than this
+	// start a new run.
+	// Default is 1 second.
+	FlushTimeout time.Duration
+
+	// RepeatedField is the name of the field holding the number of
+	// held back entries in the flushed entry.
+	// Default is "repeated".
+	RepeatedField string
+}
+
+// Handler implementation.
+//
+// The first entry in a run of entries with the same level, message and
+// fields is passed on immediately. Repeats of it are held back until the run
+// ends, that is when a different entry arrives, FlushTimeout passes or
+// Flush is called. Then a copy of the first entry is passed on
+// with the timestamp of the last repeat and a field with the number of repeats.
+//
+// Errors from the wrapped handler when flushing on timeout are ignored.
+type Handler struct {
+	opts    Options
+	handler logg.Handler
+
+	mu       sync.Mutex
+	last     *logg.Entry
+	repeated int
+	lastSeen time.Time
+	timer    *time.Timer
+	gen      int
+}
+
+// New handler deduplicating the entries passed to h.
+func New(h logg.Handler, opts Options) *Handler {
+	if opts.FlushTimeout <= 0 {
+		opts.FlushTimeout = time.Second
+	}
+	if opts.RepeatedField == "" {
+		opts.RepeatedField = "repeated"
+	}
+	return &Handler{
+		opts:    opts,
+		handler: h,
+	}
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last != nil && same(h.last, e) {
+		h.repeated++
+		h.lastSeen = e.Timestamp
+		h.timer.Reset(h.opts.FlushTimeout)
+		return nil
+	}
+
+	err := h.flushLocked()
+
+	// The entry is kept after HandleLog returns, so it must be cloned.
+	h.last = e.Clone()
+	h.gen++
+	gen := h.gen
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	h.timer = time.AfterFunc(h.opts.FlushTimeout, func() { h.timeout(gen) })
+
+	if err2 := logg.HandleLogContext(ctx, h.handler, e); err2 != nil {
+		return err2
+	}
+
+	return err
+}
+
+// Flush implements logg.Flusher.
+// Any held back entries are passed on before the wrapped handler is flushed.
+func (h *Handler) Flush(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.flushLocked()
+	h.last = nil
+	return errors.Join(err, logg.Flush(ctx, h.handler))
+}
+
+// Close implements logg.Closer.
+// Any held back entries are passed on before the wrapped handler is closed.
+func (h *Handler) Close(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.flushLocked()
+	h.last = nil
+	h.gen++
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	return errors.Join(err, logg.Close(ctx, h.handler))
+}
+
+func (h *Handler) timeout(gen int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if gen != h.gen {
+		// A newer run has started.
+		return
+	}
+	h.flushLocked()
+	h.last = nil
+}
+
+func (h *Handler) flushLocked() error {
+	if h.last == nil || h.repeated == 0 {
+		return nil
+	}
+	x := *h.last
+	x.Timestamp = h.lastSeen
+	x.Fields = append(x.Fields, logg.Int(h.opts.RepeatedField, h.repeated))
+	h.repeated = 0
+	return logg.HandleLogContext(x.Context(), h.handler, &x)
+}
+
+func same(a, b *logg.Entry) bool {
+	if a.Level != b.Level || a.Message != b.Message || a.LoggerName != b.LoggerName || len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i, f := range a.Fields {
+		if !f.Equal(b.Fields[i]) {
+			return false
+		}
+	}
+	return true
+}
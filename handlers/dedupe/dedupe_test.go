@@ -0,0 +1,104 @@
+package dedupe_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/dedupe"
+	"github.com/bep/logg/handlers/memory"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDedupe(t *testing.T) {
+	h := memory.New()
+	d := dedupe.New(h, dedupe.Options{FlushTimeout: time.Hour})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: d})
+	warn := l.WithLevel(logg.LevelWarn).WithField("dep", "db")
+
+	for range 5 {
+		warn.Log(logg.String("flaky"))
+	}
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+
+	// Different fields end the run.
+	warn.WithField("dep", "cache").Log(logg.String("flaky"))
+	qt.Assert(t, h.Entries, qt.HasLen, 3)
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{{Name: "dep", Value: "db"}})
+	qt.Assert(t, h.Entries[1].Message, qt.Equals, "flaky")
+	qt.Assert(t, h.Entries[1].Fields, qt.DeepEquals, logg.Fields{{Name: "dep", Value: "db"}, logg.Int("repeated", 4)})
+	qt.Assert(t, h.Entries[2].Fields, qt.DeepEquals, logg.Fields{{Name: "dep", Value: "cache"}})
+
+	// A single entry is not reported as repeated.
+	warn.Log(logg.String("flaky"))
+	qt.Assert(t, h.Entries, qt.HasLen, 4)
+
+	warn.Log(logg.String("flaky"))
+	qt.Assert(t, d.Flush(context.Background()), qt.IsNil)
+	qt.Assert(t, h.Entries, qt.HasLen, 5)
+	qt.Assert(t, h.Entries[4].Fields, qt.DeepEquals, logg.Fields{{Name: "dep", Value: "db"}, logg.Int("repeated", 1)})
+
+	// After a flush, a new run starts.
+	warn.Log(logg.String("flaky"))
+	qt.Assert(t, h.Entries, qt.HasLen, 6)
+}
+
+func TestDedupeClose(t *testing.T) {
+	h := memory.New()
+	d := dedupe.New(h, dedupe.Options{FlushTimeout: time.Hour})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: d})
+
+	for range 3 {
+		l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+	}
+
+	qt.Assert(t, l.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+	qt.Assert(t, h.Entries[1].Fields, qt.DeepEquals, logg.Fields{logg.Int("repeated", 2)})
+}
+
+func TestDedupeFlushTimeout(t *testing.T) {
+	entries := make(chan *logg.Entry, 10)
+	h := logg.HandlerFunc(func(e *logg.Entry) error {
+		entries <- e.Clone()
+		return nil
+	})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: dedupe.New(h, dedupe.Options{FlushTimeout: 20 * time.Millisecond})})
+	info := l.WithLevel(logg.LevelInfo)
+
+	for range 3 {
+		info.Log(logg.String("hello"))
+	}
+
+	qt.Assert(t, (<-entries).Fields, qt.HasLen, 0)
+	select {
+	case e := <-entries:
+		qt.Assert(t, e.Message, qt.Equals, "hello")
+		qt.Assert(t, e.Fields, qt.DeepEquals, logg.Fields{logg.Int("repeated", 2)})
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for flush")
+	}
+}
+
+func TestDedupeConcurrent(t *testing.T) {
+	h := memory.New()
+	d := dedupe.New(h, dedupe.Options{FlushTimeout: time.Hour})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: d})
+	info := l.WithLevel(logg.LevelInfo)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			for range 10 {
+				info.WithField("a", "b").Log(logg.String("hello"))
+			}
+		})
+	}
+	wg.Wait()
+	qt.Assert(t, d.Flush(context.Background()), qt.IsNil)
+
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+	qt.Assert(t, h.Entries[1].Fields, qt.DeepEquals, logg.Fields{{Name: "a", Value: "b"}, logg.Int("repeated", 99)})
+}
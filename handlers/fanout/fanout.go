@@ -0,0 +1,160 @@
+// Package fanout implements a handler which invokes a number of handlers concurrently.
+package fanout
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// Options holds options for the fanout handler.
+type Options struct {
+	// NoWait, if set, makes HandleLog return without waiting for the
+	// handlers to finish. Use Flush or Close to wait for them.
+	NoWait bool
+
+	// OnError, if set, is called with any error returned from the
+	// handlers when NoWait is set, except logg.ErrStopLogEntry.
+	// It may be called concurrently.
+	OnError func(e *logg.Entry, err error)
+}
+
+// Handler implementation.
+//
+// Each handler gets its own clone of the entry (see logg.Entry.Clone), so
+// they're free to modify it, including the fields in groups.
+// As the handlers run concurrently, a logg.ErrStopLogEntry returned from one
+// of them does not affect the others, and is otherwise ignored.
+type Handler struct {
+	opts     Options
+	handlers []logg.Handler
+
+	// Handlers started with NoWait set.
+	mu      sync.Mutex
+	running int
+	idle    chan struct{} // Closed when running drops to 0.
+}
+
+// New handler passing entries to h concurrently.
+func New(opts Options, h ...logg.Handler) *Handler {
+	return &Handler{
+		opts:     opts,
+		handlers: h,
+	}
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+// Unless NoWait is set, it waits for all handlers to finish and returns
+// their errors joined.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	if len(h.handlers) == 0 {
+		return nil
+	}
+
+	if h.opts.NoWait {
+		// The handlers outlive the call, but should still see the context's values.
+		ctx = context.WithoutCancel(ctx)
+		for _, handler := range h.handlers {
+			x := e.Clone()
+			h.start()
+			go func() {
+				defer h.done()
+				if err := handle(ctx, handler, x); err != nil && h.opts.OnError != nil {
+					h.opts.OnError(x, err)
+				}
+			}()
+		}
+		return nil
+	}
+
+	// The last handler runs in this goroutine with the original entry.
+	last := len(h.handlers) - 1
+	errs := make([]error, len(h.handlers))
+	var wg sync.WaitGroup
+	for i, handler := range h.handlers[:last] {
+		x := e.Clone()
+		wg.Go(func() {
+			errs[i] = handle(ctx, handler, x)
+		})
+	}
+	errs[last] = handle(ctx, h.handlers[last], e)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Flush implements logg.Flusher.
+// It waits for any running handlers and then flushes all handlers.
+func (h *Handler) Flush(ctx context.Context) error {
+	if err := h.wait(ctx); err != nil {
+		return err
+	}
+	return logg.FlushAll(ctx, h.handlers...)
+}
+
+// Close implements logg.Closer.
+// It waits for any running handlers and then closes all handlers.
+func (h *Handler) Close(ctx context.Context) error {
+	if err := h.wait(ctx); err != nil {
+		return err
+	}
+	return logg.CloseAll(ctx, h.handlers...)
+}
+
+func (h *Handler) start() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.running == 0 {
+		h.idle = make(chan struct{})
+	}
+	h.running++
+}
+
+func (h *Handler) done() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running--
+	if h.running == 0 {
+		close(h.idle)
+	}
+}
+
+// wait waits until no handlers started with NoWait set are running,
+// or until ctx is done.
+func (h *Handler) wait(ctx context.Context) error {
+	h.mu.Lock()
+	if h.running == 0 {
+		h.mu.Unlock()
+		return nil
+	}
+	idle := h.idle
+	h.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func handle(ctx context.Context, h logg.Handler, e *logg.Entry) error {
+	if err := logg.HandleLogContext(ctx, h, e); err != logg.ErrStopLogEntry {
+		return err
+	}
+	return nil
+}
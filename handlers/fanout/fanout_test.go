@@ -0,0 +1,140 @@
+package fanout_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/fanout"
+	"github.com/bep/logg/handlers/memory"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFanout(t *testing.T) {
+	// All handlers must be running at the same time to pass the barrier.
+	var barrier sync.WaitGroup
+	barrier.Add(3)
+	var seen []string
+	var mu sync.Mutex
+	newHandler := func(name string) logg.Handler {
+		return logg.HandlerFunc(func(e *logg.Entry) error {
+			barrier.Done()
+			barrier.Wait()
+			e.Fields[0].Value = name
+			mu.Lock()
+			seen = append(seen, e.Fields[0].Value.(string))
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	h := fanout.New(fanout.Options{}, newHandler("a"), newHandler("b"), newHandler("c"))
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	done := make(chan struct{})
+	go func() {
+		l.WithLevel(logg.LevelInfo).WithField("name", "").Log(logg.String("hello"))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handlers did not run concurrently")
+	}
+
+	qt.Assert(t, seen, qt.ContentEquals, []string{"a", "b", "c"})
+}
+
+func TestFanoutErrors(t *testing.T) {
+	errFail := errors.New("fail")
+	a := memory.New()
+	h := fanout.New(fanout.Options{},
+		logg.HandlerFunc(func(e *logg.Entry) error { return errFail }),
+		logg.HandlerFunc(func(e *logg.Entry) error { return logg.ErrStopLogEntry }),
+		a,
+	)
+
+	qt.Assert(t, h.HandleLog(&logg.Entry{Message: "hello"}), qt.ErrorIs, errFail)
+	qt.Assert(t, a.Entries, qt.HasLen, 1)
+	qt.Assert(t, fanout.New(fanout.Options{}).HandleLog(&logg.Entry{}), qt.IsNil)
+}
+
+func TestFanoutNoWait(t *testing.T) {
+	errFail := errors.New("fail")
+	var errs []error
+	var mu sync.Mutex
+	a, b := memory.New(), memory.New()
+	h := fanout.New(
+		fanout.Options{
+			NoWait: true,
+			OnError: func(e *logg.Entry, err error) {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			},
+		},
+		a, b,
+		logg.HandlerFunc(func(e *logg.Entry) error { return errFail }),
+	)
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	for range 10 {
+		l.WithLevel(logg.LevelInfo).WithField("user", "tj").Log(logg.String("hello"))
+	}
+
+	qt.Assert(t, l.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, a.Entries, qt.HasLen, 10)
+	qt.Assert(t, b.Entries, qt.HasLen, 10)
+	qt.Assert(t, b.Entries[9].Fields, qt.DeepEquals, logg.Fields{{Name: "user", Value: "tj"}})
+	qt.Assert(t, errs, qt.HasLen, 10)
+}
+
+func TestFanoutNoWaitFlushWhileLogging(t *testing.T) {
+	release := make(chan struct{})
+	stuck := logg.HandlerFunc(func(e *logg.Entry) error {
+		if e.Message == "stuck" {
+			<-release
+		}
+		return nil
+	})
+	h := fanout.New(fanout.Options{NoWait: true}, stuck)
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	// Flush while logging continues.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+			}
+		})
+	}
+	for range 10 {
+		qt.Assert(t, l.Flush(context.Background()), qt.IsNil)
+	}
+	wg.Wait()
+
+	// Flush gives up when ctx is done.
+	l.WithLevel(logg.LevelInfo).Log(logg.String("stuck"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	qt.Assert(t, l.Flush(ctx), qt.ErrorIs, context.DeadlineExceeded)
+
+	close(release)
+	qt.Assert(t, l.Close(context.Background()), qt.IsNil)
+}
+
+func BenchmarkFanout(b *testing.B) {
+	discard := logg.HandlerFunc(func(e *logg.Entry) error { return nil })
+	h := fanout.New(fanout.Options{}, discard, discard, discard)
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+	info := l.WithLevel(logg.LevelInfo).WithField("user", "tj")
+
+	for b.Loop() {
+		info.Log(logg.String("hello"))
+	}
+}
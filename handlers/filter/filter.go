@@ -0,0 +1,371 @@
+// Package filter implements a handler which passes on the log entries
+// matching a filter expression, e.g.
+//
+//	level >= warn && (component == "db" || duration > 500)
+//
+// An expression is a combination of comparisons, joined with "&&" (and)
+// and "||" (or), negated with "!" and grouped with parentheses.
+//
+// The left side of a comparison is "level", "message" or a field name.
+// Field names may contain letters, digits, "_", "." and "-", and if a field
+// is set more than once, the last value is used.
+// Fields in groups are matched with dotted names, e.g. http.status for the
+// field status in the group http.
+// A field name on its own matches entries with that field set.
+//
+// The right side of a comparison is a value:
+//
+//   - level: a level name, e.g. warn or "warn".
+//   - message: a quoted string, e.g. "GET /".
+//   - fields: a quoted string, a number (e.g. 500 or 0.5), a duration
+//     (e.g. 500ms or 1m30s) or true or false.
+//
+// The comparison operators are ==, !=, <, <=, >, >=, =~ (matches regular
+// expression) and !~ (does not match regular expression).
+// Numbers match integer and floating point field values, durations match
+// time.Duration field values, and strings and regular expressions match
+// string, error and fmt.Stringer field values.
+// A comparison with a missing field or a field with a value of another type
+// does not match, also for != and !~.
+package filter
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// SyntaxError is returned by Compile for invalid expressions.
+type SyntaxError struct {
+	Expr   string
+	Offset int // Byte offset of the error in Expr.
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: %s at offset %d in %q", e.Msg, e.Offset, e.Expr)
+}
+
+// Filter is a compiled filter expression.
+// It is safe for concurrent use.
+type Filter struct {
+	expr  string
+	match matcher
+}
+
+// Compile parses a filter expression, see the package documentation.
+func Compile(expr string) (*Filter, error) {
+	m, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{expr: expr, match: m}, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func MustCompile(expr string) *Filter {
+	f, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Match reports whether e matches f.
+// It can be used as a route.Predicate.
+func (f *Filter) Match(e *logg.Entry) bool {
+	return f.match(e)
+}
+
+// String returns the expression f was compiled from.
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Handler implementation.
+type Handler struct {
+	filter  *Filter
+	handler logg.Handler
+}
+
+// New handler passing the entries matching f to h.
+func New(h logg.Handler, f *Filter) *Handler {
+	return &Handler{
+		filter:  f,
+		handler: h,
+	}
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	if !h.filter.Match(e) {
+		return nil
+	}
+
+	return logg.HandleLogContext(ctx, h.handler, e)
+}
+
+// Flush implements logg.Flusher by flushing the wrapped handler.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.Flush(ctx, h.handler)
+}
+
+// Close implements logg.Closer by closing the wrapped handler.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.Close(ctx, h.handler)
+}
+
+type matcher func(e *logg.Entry) bool
+
+func and(a, b matcher) matcher {
+	return func(e *logg.Entry) bool { return a(e) && b(e) }
+}
+
+func or(a, b matcher) matcher {
+	return func(e *logg.Entry) bool { return a(e) || b(e) }
+}
+
+func not(m matcher) matcher {
+	return func(e *logg.Entry) bool { return !m(e) }
+}
+
+type operator int
+
+const (
+	opEq operator = iota
+	opNe
+	opLt
+	opLe
+	opGt
+	opGe
+	opMatch
+	opNotMatch
+)
+
+var operators = map[string]operator{
+	"==": opEq,
+	"!=": opNe,
+	"<":  opLt,
+	"<=": opLe,
+	">":  opGt,
+	">=": opGe,
+	"=~": opMatch,
+	"!~": opNotMatch,
+}
+
+// eval reports whether c, the result of a cmp.Compare, satisfies o.
+func (o operator) eval(c int) bool {
+	switch o {
+	case opEq:
+		return c == 0
+	case opNe:
+		return c != 0
+	case opLt:
+		return c < 0
+	case opLe:
+		return c <= 0
+	case opGt:
+		return c > 0
+	case opGe:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+func compareLevel(o operator, level logg.Level) matcher {
+	return func(e *logg.Entry) bool {
+		return o.eval(cmp.Compare(e.Level, level))
+	}
+}
+
+func compareMessage(o operator, s string) matcher {
+	return func(e *logg.Entry) bool {
+		return o.eval(cmp.Compare(e.Message, s))
+	}
+}
+
+func hasField(name string) matcher {
+	return func(e *logg.Entry) bool {
+		_, found := e.Fields.Lookup(name)
+		return found
+	}
+}
+
+func matchRegexp(name string, re *regexp.Regexp, negate bool) matcher {
+	if name == "message" {
+		return func(e *logg.Entry) bool {
+			return re.MatchString(e.Message) != negate
+		}
+	}
+	return func(e *logg.Entry) bool {
+		f, found := e.Fields.Lookup(name)
+		if !found {
+			return false
+		}
+		s, ok := stringValue(f)
+		return ok && re.MatchString(s) != negate
+	}
+}
+
+func compareString(name string, o operator, s string) matcher {
+	return func(e *logg.Entry) bool {
+		f, found := e.Fields.Lookup(name)
+		if !found {
+			return false
+		}
+		v, ok := stringValue(f)
+		return ok && o.eval(cmp.Compare(v, s))
+	}
+}
+
+func compareInt(name string, o operator, i int64) matcher {
+	return func(e *logg.Entry) bool {
+		f, found := e.Fields.Lookup(name)
+		if !found {
+			return false
+		}
+		vi, vf, isFloat, ok := numberValue(f)
+		if !ok {
+			return false
+		}
+		if isFloat {
+			return o.eval(cmp.Compare(vf, float64(i)))
+		}
+		return o.eval(cmp.Compare(vi, i))
+	}
+}
+
+func compareFloat(name string, o operator, fl float64) matcher {
+	return func(e *logg.Entry) bool {
+		f, found := e.Fields.Lookup(name)
+		if !found {
+			return false
+		}
+		vi, vf, isFloat, ok := numberValue(f)
+		if !ok {
+			return false
+		}
+		if !isFloat {
+			vf = float64(vi)
+		}
+		return o.eval(cmp.Compare(vf, fl))
+	}
+}
+
+func compareDuration(name string, o operator, d time.Duration) matcher {
+	return func(e *logg.Entry) bool {
+		f, found := e.Fields.Lookup(name)
+		if !found {
+			return false
+		}
+		v, ok := durationValue(f)
+		return ok && o.eval(cmp.Compare(v, d))
+	}
+}
+
+func compareBool(name string, o operator, b bool) matcher {
+	return func(e *logg.Entry) bool {
+		f, found := e.Fields.Lookup(name)
+		if !found {
+			return false
+		}
+		v, ok := boolValue(f)
+		return ok && (v == b) == (o == opEq)
+	}
+}
+
+func stringValue(f logg.Field) (string, bool) {
+	if f.Kind() == logg.KindString {
+		return f.Str(), true
+	}
+	switch v := f.Value.(type) {
+	case string:
+		return v, true
+	case error:
+		return v.Error(), true
+	case fmt.Stringer:
+		return v.String(), true
+	}
+	return "", false
+}
+
+func numberValue(f logg.Field) (i int64, fl float64, isFloat, ok bool) {
+	switch f.Kind() {
+	case logg.KindInt64:
+		return f.Int64(), 0, false, true
+	case logg.KindFloat64:
+		return 0, f.Float64(), true, true
+	case logg.KindAny:
+	default:
+		return 0, 0, false, false
+	}
+
+	switch v := f.Value.(type) {
+	case int:
+		return int64(v), 0, false, true
+	case int8:
+		return int64(v), 0, false, true
+	case int16:
+		return int64(v), 0, false, true
+	case int32:
+		return int64(v), 0, false, true
+	case int64:
+		return v, 0, false, true
+	case uint:
+		return uintValue(uint64(v))
+	case uint8:
+		return int64(v), 0, false, true
+	case uint16:
+		return int64(v), 0, false, true
+	case uint32:
+		return int64(v), 0, false, true
+	case uint64:
+		return uintValue(v)
+	case float32:
+		return 0, float64(v), true, true
+	case float64:
+		return 0, v, true, true
+	}
+	return 0, 0, false, false
+}
+
+func uintValue(v uint64) (int64, float64, bool, bool) {
+	if v > math.MaxInt64 {
+		return 0, float64(v), true, true
+	}
+	return int64(v), 0, false, true
+}
+
+func durationValue(f logg.Field) (time.Duration, bool) {
+	if f.Kind() == logg.KindDuration {
+		return f.Duration(), true
+	}
+	d, ok := f.Value.(time.Duration)
+	return d, ok
+}
+
+func boolValue(f logg.Field) (bool, bool) {
+	if f.Kind() == logg.KindBool {
+		return f.Bool(), true
+	}
+	b, ok := f.Value.(bool)
+	return b, ok
+}
@@ -0,0 +1,162 @@
+package filter_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/filter"
+	"github.com/bep/logg/handlers/memory"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFilter(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{
+		Level:   logg.LevelTrace,
+		Handler: filter.New(h, filter.MustCompile(`level>=warn && (component == "db" || duration > 500)`)),
+	})
+
+	l.WithLevel(logg.LevelError).WithField("component", "db").Log(logg.String("a"))
+	l.WithLevel(logg.LevelWarn).WithField("component", "http").WithDuration(time.Second).Log(logg.String("b"))
+	l.WithLevel(logg.LevelWarn).WithField("component", "http").WithDuration(time.Millisecond).Log(logg.String("c"))
+	l.WithLevel(logg.LevelInfo).WithField("component", "db").Log(logg.String("d"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+	qt.Assert(t, h.Entries[0].Message, qt.Equals, "a")
+	qt.Assert(t, h.Entries[1].Message, qt.Equals, "b")
+}
+
+func TestFilterMatch(t *testing.T) {
+	e := &logg.Entry{
+		Level:   logg.LevelWarn,
+		Message: "GET /about",
+		Fields: logg.Fields{
+			logg.Str("component", "http"),
+			logg.Int("status", 500),
+			{Name: "size", Value: uint32(1024)},
+			logg.Float64("ratio", 0.5),
+			logg.Duration("elapsed", 1500*time.Millisecond),
+			{Name: "timeout", Value: 2 * time.Second},
+			logg.Bool("cached", false),
+			{Name: "error", Value: errors.New("connection reset")},
+			logg.Str("component", "db"),
+			logg.Group("http", logg.Str("method", "GET"), logg.Group("res", logg.Int("status", 503))),
+		},
+	}
+
+	for _, test := range []struct {
+		expr     string
+		expected bool
+	}{
+		{`level == warn`, true},
+		{`level >= "error"`, false},
+		{`level < error`, true},
+		{`message == "GET /about"`, true},
+		{`message =~ "^GET /a"`, true},
+		{`message !~ "^GET"`, false},
+		{`message > "A"`, true},
+		{`component == "db"`, true},
+		{`component == "http"`, false},
+		{`component != "http"`, true},
+		{"component =~ `^d.$`", true},
+		{`status == 500`, true},
+		{`status >= 500.5`, false},
+		{`status > -1`, true},
+		{`size > 1000`, true},
+		{`size <= 1e3`, false},
+		{`ratio < 1`, true},
+		{`ratio == 0.5`, true},
+		{`elapsed > 1s`, true},
+		{`elapsed > 1m30s`, false},
+		{`timeout == 2000ms`, true},
+		{`elapsed > 500`, false},
+		{`cached == false`, true},
+		{`cached != false`, false},
+		{`error =~ "reset"`, true},
+		{`error`, true},
+		{`user`, false},
+		{`!user`, true},
+		{`user != "tj"`, false},
+		{`user !~ "tj"`, false},
+		{`component == 1`, false},
+		{`http.method == "GET"`, true},
+		{`http.res.status >= 500`, true},
+		{`http.res`, true},
+		{`http.status`, false},
+		{`res.status`, false},
+		{`!(level >= error || status < 500) && (cached == true || ratio > 0.1)`, true},
+		{`level >= error || cached == false && status == 500`, true},
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			f, err := filter.Compile(test.expr)
+			qt.Assert(t, err, qt.IsNil)
+			qt.Assert(t, f.Match(e), qt.Equals, test.expected)
+			qt.Assert(t, f.String(), qt.Equals, test.expr)
+		})
+	}
+}
+
+func TestFilterSyntaxError(t *testing.T) {
+	for _, test := range []struct {
+		expr string
+		err  string
+	}{
+		{``, `filter: empty expression at offset 0 in ""`},
+		{`level >= `, `filter: unexpected end of expression, expected a value after ">=" at offset 9 in "level >= "`},
+		{`level >= loud`, `filter: unknown level "loud", expected one of trace, debug, info, notice, warn, error, critical, panic, fatal at offset 9 in "level >= loud"`},
+		{`level =~ "w"`, `filter: level can not be matched with "=~" at offset 6 in "level =~ \"w\""`},
+		{`level`, `filter: "level" must be compared to a value at offset 0 in "level"`},
+		{`message == 1`, `filter: message can only be compared to a string, got "1" at offset 11 in "message == 1"`},
+		{`component = "db"`, `filter: unexpected "=", use "==" for equality at offset 10 in "component = \"db\""`},
+		{`component == db`, `filter: unexpected "db", expected a string, number, duration or boolean; strings must be quoted at offset 13 in "component == db"`},
+		{`component == "db`, `filter: unterminated string at offset 13 in "component == \"db"`},
+		{`(level >= warn`, `filter: unexpected end of expression, expected ")" at offset 14 in "(level >= warn"`},
+		{`level >= warn)`, `filter: unexpected ")", expected "&&", "||" or end of expression at offset 13 in "level >= warn)"`},
+		{`level >= warn &&`, `filter: unexpected end of expression, expected a field name, "level", "message", "!" or "(" at offset 16 in "level >= warn &&"`},
+		{`duration > 5x`, `filter: invalid number or duration "5x" at offset 11 in "duration > 5x"`},
+		{`cached > true`, `filter: booleans can only be compared with "==" and "!=" at offset 7 in "cached > true"`},
+		{`message =~ "["`, "filter: invalid regular expression: error parsing regexp: missing closing ]: `[` at offset 11 in \"message =~ \\\"[\\\"\""},
+		{`message =~ 1`, `filter: "=~" must be followed by a regular expression string, got "1" at offset 11 in "message =~ 1"`},
+		{`a # b`, `filter: unexpected character '#' at offset 2 in "a # b"`},
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			_, err := filter.Compile(test.expr)
+			qt.Assert(t, err, qt.ErrorMatches, regexp.QuoteMeta(test.err))
+			var serr *filter.SyntaxError
+			qt.Assert(t, errors.As(err, &serr), qt.IsTrue)
+		})
+	}
+}
+
+func TestFilterAllocs(t *testing.T) {
+	f := filter.MustCompile(`level >= warn && (component == "db" || duration > 500 || elapsed > 1s)`)
+	e := &logg.Entry{
+		Level:  logg.LevelWarn,
+		Fields: logg.Fields{logg.Str("component", "http"), logg.Int64("duration", 200), logg.Duration("elapsed", time.Minute)},
+	}
+
+	qt.Assert(t, f.Match(e), qt.IsTrue)
+	qt.Assert(t, testing.AllocsPerRun(100, func() { f.Match(e) }), qt.Equals, 0.0)
+}
+
+func TestFilterFlushClose(t *testing.T) {
+	h := filter.New(memory.New(), filter.MustCompile(`level >= warn`))
+	qt.Assert(t, h.Flush(context.Background()), qt.IsNil)
+	qt.Assert(t, h.Close(context.Background()), qt.IsNil)
+}
+
+func BenchmarkFilter(b *testing.B) {
+	f := filter.MustCompile(`level >= warn && (component == "db" || duration > 500)`)
+	e := &logg.Entry{
+		Level:  logg.LevelWarn,
+		Fields: logg.Fields{logg.Str("user", "tj"), logg.Str("component", "http"), logg.Int64("duration", 200)},
+	}
+
+	for b.Loop() {
+		f.Match(e)
+	}
+}
@@ -0,0 +1,345 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bep/logg"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	expr   string
+	pos    int
+	tokens []token
+}
+
+func lex(expr string) ([]token, error) {
+	l := &lexer{expr: expr}
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, t)
+		if t.kind == tokenEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.expr) && isSpace(l.expr[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.expr) {
+		return token{kind: tokenEOF, offset: start}, nil
+	}
+
+	c := l.expr[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokenLParen, text: "(", offset: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokenRParen, text: ")", offset: start}, nil
+	case c == '"' || c == '`':
+		s, err := strconv.QuotedPrefix(l.expr[l.pos:])
+		if err != nil {
+			return token{}, &SyntaxError{Expr: l.expr, Offset: start, Msg: "unterminated string"}
+		}
+		l.pos += len(s)
+		return token{kind: tokenString, text: s, offset: start}, nil
+	case isDigit(c) || (c == '-' || c == '.') && l.pos+1 < len(l.expr) && isDigit(l.expr[l.pos+1]):
+		l.pos++
+		for l.pos < len(l.expr) && (isIdent(l.expr[l.pos]) || l.expr[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokenNumber, text: l.expr[start:l.pos], offset: start}, nil
+	case isIdentStart(c):
+		for l.pos < len(l.expr) && (isIdent(l.expr[l.pos]) || l.expr[l.pos] == '.' || l.expr[l.pos] == '-') {
+			l.pos++
+		}
+		return token{kind: tokenIdent, text: l.expr[start:l.pos], offset: start}, nil
+	}
+
+	for _, op := range [...]string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!"} {
+		if strings.HasPrefix(l.expr[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokenOp, text: op, offset: start}, nil
+		}
+	}
+
+	if c == '=' {
+		return token{}, &SyntaxError{Expr: l.expr, Offset: start, Msg: `unexpected "=", use "==" for equality`}
+	}
+	r, _ := utf8.DecodeRuneInString(l.expr[l.pos:])
+	return token{}, &SyntaxError{Expr: l.expr, Offset: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdent(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// parser is a recursive descent parser for the grammar:
+//
+//	expr       = and { "||" and } .
+//	and        = unary { "&&" unary } .
+//	unary      = "!" unary | primary .
+//	primary    = "(" expr ")" | ident [ op value ] .
+//	op         = "==" | "!=" | "<" | "<=" | ">" | ">=" | "=~" | "!~" .
+//	value      = string | number | duration | ident .
+type parser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+func parse(expr string) (matcher, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expr: expr, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "empty expression")
+	}
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %s, expected \"&&\", \"||\" or end of expression", t)
+	}
+	return m, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &SyntaxError{Expr: p.expr, Offset: t.offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (matcher, error) {
+	m, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOp && p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		m = or(m, right)
+	}
+	return m, nil
+}
+
+func (p *parser) parseAnd() (matcher, error) {
+	m, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOp && p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		m = and(m, right)
+	}
+	return m, nil
+}
+
+func (p *parser) parseUnary() (matcher, error) {
+	if t := p.peek(); t.kind == tokenOp && t.text == "!" {
+		p.next()
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not(m), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (matcher, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if rt := p.next(); rt.kind != tokenRParen {
+			return nil, p.errorf(rt, "unexpected %s, expected \")\"", rt)
+		}
+		return m, nil
+	case tokenIdent:
+		op := p.peek()
+		if op.kind != tokenOp || !isComparison(op.text) {
+			// A field name on its own tests for its presence.
+			return p.field(t)
+		}
+		p.next()
+		v := p.next()
+		switch v.kind {
+		case tokenString, tokenNumber, tokenIdent:
+		default:
+			return nil, p.errorf(v, "unexpected %s, expected a value after %q", v, op.text)
+		}
+		return p.comparison(t, op, v)
+	default:
+		return nil, p.errorf(t, "unexpected %s, expected a field name, \"level\", \"message\", \"!\" or \"(\"", t)
+	}
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~", "!~":
+		return true
+	}
+	return false
+}
+
+func (p *parser) field(name token) (matcher, error) {
+	switch name.text {
+	case "level", "message":
+		return nil, p.errorf(name, "%s must be compared to a value", name)
+	}
+	return hasField(name.text), nil
+}
+
+func (p *parser) comparison(name, op, v token) (matcher, error) {
+	o := operators[op.text]
+
+	if o == opMatch || o == opNotMatch {
+		if v.kind != tokenString {
+			return nil, p.errorf(v, "%q must be followed by a regular expression string, got %s", op.text, v)
+		}
+		s, _ := strconv.Unquote(v.text)
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return nil, p.errorf(v, "invalid regular expression: %s", err)
+		}
+		if name.text == "level" {
+			return nil, p.errorf(op, "level can not be matched with %q", op.text)
+		}
+		return matchRegexp(name.text, re, o == opNotMatch), nil
+	}
+
+	switch name.text {
+	case "level":
+		level, err := parseLevel(v)
+		if err != nil {
+			return nil, p.errorf(v, "%s", err)
+		}
+		return compareLevel(o, level), nil
+	case "message":
+		if v.kind != tokenString {
+			return nil, p.errorf(v, "message can only be compared to a string, got %s", v)
+		}
+		s, _ := strconv.Unquote(v.text)
+		return compareMessage(o, s), nil
+	}
+
+	switch v.kind {
+	case tokenString:
+		s, _ := strconv.Unquote(v.text)
+		return compareString(name.text, o, s), nil
+	case tokenNumber:
+		if i, err := strconv.ParseInt(v.text, 10, 64); err == nil {
+			return compareInt(name.text, o, i), nil
+		}
+		if f, err := strconv.ParseFloat(v.text, 64); err == nil {
+			return compareFloat(name.text, o, f), nil
+		}
+		if d, err := time.ParseDuration(v.text); err == nil {
+			return compareDuration(name.text, o, d), nil
+		}
+		return nil, p.errorf(v, "invalid number or duration %s", v)
+	default:
+		switch v.text {
+		case "true", "false":
+			if o != opEq && o != opNe {
+				return nil, p.errorf(op, "booleans can only be compared with \"==\" and \"!=\"")
+			}
+			return compareBool(name.text, o, v.text == "true"), nil
+		}
+		return nil, p.errorf(v, "unexpected %s, expected a string, number, duration or boolean; strings must be quoted", v)
+	}
+}
+
+func parseLevel(v token) (logg.Level, error) {
+	s := v.text
+	if v.kind == tokenString {
+		s, _ = strconv.Unquote(s)
+	}
+	level, err := logg.ParseLevel(s)
+	if err != nil {
+		return 0, fmt.Errorf("unknown level %s, expected one of %s", v, levelNames())
+	}
+	return level, nil
+}
+
+// levelNames returns the names of the registered levels, comma separated.
+func levelNames() string {
+	var names []string
+	for _, spec := range logg.Levels() {
+		names = append(names, spec.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -2,8 +2,14 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/bep/logg"
 )
@@ -16,15 +22,266 @@ type Handler struct {
 // Eeach log Entry is written as a single JSON object, no more than one write to w.
 // The writer w should be safe for concurrent use by multiple
 // goroutines if the returned Handler will be used concurrently.
+//
+// The output is the same as encoding/json would produce for the Entry,
+// but the fields created with the typed constructors (e.g. logg.Int64)
+// and values of the most common types are encoded without reflection.
 func New(w io.Writer) *Handler {
 	return &Handler{
 		w,
 	}
 }
 
+var bufPool = &sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
 // HandleLog implements logg.Handler.
 func (h *Handler) HandleLog(e *logg.Entry) error {
-	enc := json.NewEncoder(h.w)
+	bp := bufPool.Get().(*[]byte)
+	defer func() {
+		*bp = (*bp)[:0]
+		bufPool.Put(bp)
+	}()
+
+	b, err := appendEntry(*bp, e)
+	*bp = b
+	if err != nil {
+		return err
+	}
+
+	_, err = h.w.Write(b)
+	return err
+}
+
+func appendEntry(b []byte, e *logg.Entry) ([]byte, error) {
+	b = append(b, `{"level":`...)
+	b = appendString(b, e.Level.String())
+	if e.LoggerName != "" {
+		b = append(b, `,"logger":`...)
+		b = appendString(b, e.LoggerName)
+	}
+	b = append(b, `,"timestamp":"`...)
+	b = e.Timestamp.AppendFormat(b, time.RFC3339Nano)
+	b = append(b, '"')
+
+	if len(e.Fields) > 0 {
+		b = append(b, `,"fields":[`...)
+		for i, f := range e.Fields {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, `{"name":`...)
+			b = appendString(b, f.Name)
+			b = append(b, `,"value":`...)
+			var err error
+			b, err = appendValue(b, f)
+			if err != nil {
+				return b, err
+			}
+			b = append(b, '}')
+		}
+		b = append(b, ']')
+	}
+
+	b = append(b, `,"message":`...)
+	b = appendString(b, e.Message)
+
+	if e.Source != (logg.Frame{}) {
+		b = append(b, `,"source":`...)
+		b = appendFrame(b, e.Source)
+	}
+
+	if len(e.Stack) > 0 {
+		b = append(b, `,"stack":[`...)
+		for i, f := range e.Stack {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendFrame(b, f)
+		}
+		b = append(b, ']')
+	}
+
+	b = append(b, "}\n"...)
+
+	return b, nil
+}
+
+func appendFrame(b []byte, f logg.Frame) []byte {
+	b = append(b, `{"function":`...)
+	b = appendString(b, f.Function)
+	b = append(b, `,"file":`...)
+	b = appendString(b, f.File)
+	b = append(b, `,"line":`...)
+	b = strconv.AppendInt(b, int64(f.Line), 10)
+	return append(b, '}')
+}
+
+func appendValue(b []byte, f logg.Field) ([]byte, error) {
+	switch f.Kind() {
+	case logg.KindString:
+		return appendString(b, f.Str()), nil
+	case logg.KindInt64:
+		return strconv.AppendInt(b, f.Int64(), 10), nil
+	case logg.KindFloat64:
+		return appendFloat(b, f.Float64()), nil
+	case logg.KindBool:
+		return strconv.AppendBool(b, f.Bool()), nil
+	case logg.KindDuration:
+		return strconv.AppendInt(b, int64(f.Duration()), 10), nil
+	case logg.KindTime:
+		return appendTime(b, f.Time()), nil
+	case logg.KindGroup:
+		return appendGroup(b, f.Group())
+	default:
+		return appendAny(b, f.Value)
+	}
+}
+
+// appendGroup appends the fields in a group as an object.
+func appendGroup(b []byte, fields logg.Fields) ([]byte, error) {
+	b = append(b, '{')
+	for i, f := range fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendString(b, f.Name)
+		b = append(b, ':')
+		var err error
+		b, err = appendValue(b, f)
+		if err != nil {
+			return b, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+func appendAny(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case string:
+		return appendString(b, v), nil
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case uint64:
+		return strconv.AppendUint(b, v, 10), nil
+	case float64:
+		return appendFloat(b, v), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case time.Duration:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case time.Time:
+		return appendTime(b, v), nil
+	case logg.SecretValue:
+		return appendString(b, v.String()), nil
+	case json.Marshaler:
+		return appendMarshal(b, v)
+	case error:
+		return appendString(b, v.Error()), nil
+	default:
+		return appendMarshal(b, v)
+	}
+}
+
+// appendMarshal appends v encoded with encoding/json without HTML escaping.
+func appendMarshal(b []byte, v any) ([]byte, error) {
+	buf := bytes.NewBuffer(b)
+	enc := json.NewEncoder(buf)
 	enc.SetEscapeHTML(false)
-	return enc.Encode(e)
+	if err := enc.Encode(v); err != nil {
+		return b, err
+	}
+	// Remove the newline added by Encode.
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func appendTime(b []byte, t time.Time) []byte {
+	b = append(b, '"')
+	b = t.AppendFormat(b, time.RFC3339Nano)
+	return append(b, '"')
+}
+
+// appendFloat appends f in the same format as encoding/json,
+// but with NaN and infinities as strings.
+func appendFloat(b []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendString(b, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends s as a JSON string, escaped the same way as
+// encoding/json with HTML escaping disabled.
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = utf8.AppendRune(b, utf8.RuneError)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
 }
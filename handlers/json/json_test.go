@@ -2,7 +2,11 @@ package json_test
 
 import (
 	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"io"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 
@@ -31,3 +35,140 @@ func TestJSONHandler(t *testing.T) {
 
 	qt.Assert(t, buf.String(), qt.Equals, expected)
 }
+
+func TestJSONHandlerNamed(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logg.New(
+		logg.Options{
+			Level:   logg.LevelInfo,
+			Handler: json.New(&buf),
+			Clock:   clocks.Fixed(clocks.TimeCupFinalNorway1976),
+		})
+
+	l.Named("cache").WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Equals, "{\"level\":\"info\",\"logger\":\"cache\",\"timestamp\":\"1976-10-24T12:15:02.127686412Z\",\"message\":\"hello\"}\n")
+}
+
+func TestJSONHandlerSource(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logg.New(
+		logg.Options{
+			Level:     logg.LevelInfo,
+			Handler:   json.New(&buf),
+			Clock:     clocks.Fixed(clocks.TimeCupFinalNorway1976),
+			AddSource: true,
+		})
+
+	l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Matches, `.*"message":"hello","source":\{"function":"github.com/bep/logg/handlers/json_test.TestJSONHandlerSource","file":".*json_test.go","line":\d+\}\}\n`)
+}
+
+func TestJSONHandlerTypedFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logg.New(
+		logg.Options{
+			Level:   logg.LevelInfo,
+			Handler: json.New(&buf),
+			Clock:   clocks.Fixed(clocks.TimeCupFinalNorway1976),
+		})
+
+	tm := time.Date(2022, 8, 12, 10, 0, 0, 0, time.UTC)
+
+	fields := logg.Fields{
+		logg.Str("string", "a \"quoted\"\n\tstring  with <html> & \x01 \xff"),
+		logg.Int64("int64", -42),
+		logg.Float64("float64", 1.5),
+		logg.Float64("small", 1e-9),
+		logg.Float64("large", 1e21),
+		logg.Bool("bool", true),
+		logg.Duration("duration", time.Second),
+		logg.Time("time", tm),
+		logg.Err(errors.New("boom")),
+		logg.Any("slice", []string{"a", "b"}),
+		logg.Any("map", map[string]int{"a": 1}),
+		logg.Any("struct", struct{ A string }{"<b>&"}),
+		logg.Any("html", map[string]string{"<a>": "x & y"}),
+		logg.Any("nil", nil),
+		{Name: "uint", Value: uint(32)},
+		logg.Group("http", logg.Str("method", "GET"), logg.Group("res", logg.Int("status", 200), logg.Err(errors.New("<nil>")))),
+		logg.Group("empty"),
+	}
+
+	l.WithLevel(logg.LevelInfo).WithFields(fields).Log(logg.String("hello\x00"))
+
+	// Verify that the output is the same as from encoding/json.
+	e := &logg.Entry{
+		Level:     logg.LevelInfo,
+		Timestamp: clocks.TimeCupFinalNorway1976,
+		Fields:    fields,
+		Message:   "hello\x00",
+	}
+	var expected bytes.Buffer
+	enc := stdjson.NewEncoder(&expected)
+	enc.SetEscapeHTML(false)
+	qt.Assert(t, enc.Encode(e), qt.IsNil)
+
+	qt.Assert(t, buf.String(), qt.Equals, expected.String())
+	qt.Assert(t, buf.String(), qt.Contains, `{"A":"<b>&"}`)
+}
+
+func TestJSONHandlerGroups(t *testing.T) {
+	var buf bytes.Buffer
+	l := logg.New(
+		logg.Options{
+			Level:   logg.LevelInfo,
+			Handler: json.New(&buf),
+			Clock:   clocks.Fixed(clocks.TimeCupFinalNorway1976),
+		})
+
+	l.WithLevel(logg.LevelInfo).WithGroup("http").WithField("method", "GET").WithField("status", 200).Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Equals, `{"level":"info","timestamp":"1976-10-24T12:15:02.127686412Z","fields":[{"name":"http","value":{"method":"GET","status":200}}],"message":"hello"}`+"\n")
+}
+
+func TestJSONHandlerAllocs(t *testing.T) {
+	h := json.New(io.Discard)
+	e := &logg.Entry{
+		Level:     logg.LevelInfo,
+		Timestamp: clocks.TimeCupFinalNorway1976,
+		Fields: logg.Fields{
+			logg.Str("string", "value"),
+			logg.Int64("int64", 42),
+			logg.Bool("bool", true),
+			logg.Duration("duration", time.Second),
+			logg.Time("time", clocks.TimeCupFinalNorway1976),
+		},
+		Message: "hello",
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := h.HandleLog(e); err != nil {
+			t.Fatal(err)
+		}
+	})
+	qt.Assert(t, allocs, qt.Equals, 0.0)
+}
+
+func BenchmarkJSONHandler(b *testing.B) {
+	h := json.New(io.Discard)
+	e := &logg.Entry{
+		Level:     logg.LevelInfo,
+		Timestamp: clocks.TimeCupFinalNorway1976,
+		Fields: logg.Fields{
+			logg.Str("string", "value"),
+			logg.Int64("int64", 42),
+			logg.Duration("duration", time.Second),
+			{Name: "any", Value: "value"},
+		},
+		Message: "hello",
+	}
+
+	for b.Loop() {
+		h.HandleLog(e)
+	}
+}
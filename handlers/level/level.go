@@ -1,7 +1,18 @@
 // Package level implements a level filter handler.
 package level
 
-import "github.com/bep/logg"
+import (
+	"context"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
 
 // Handler implementation.
 type Handler struct {
@@ -19,9 +30,24 @@ func New(h logg.Handler, level logg.Level) *Handler {
 
 // HandleLog implements logg.Handler.
 func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
 	if e.Level < h.Level {
 		return nil
 	}
 
-	return h.Handler.HandleLog(e)
+	return logg.HandleLogContext(ctx, h.Handler, e)
+}
+
+// Flush implements logg.Flusher by flushing the wrapped handler.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.Flush(ctx, h.Handler)
+}
+
+// Close implements logg.Closer by closing the wrapped handler.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.Close(ctx, h.Handler)
 }
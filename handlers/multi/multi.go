@@ -2,31 +2,101 @@
 package multi
 
 import (
+	"context"
+	"errors"
+
 	"github.com/bep/logg"
 )
 
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// Mode decides how errors from the handlers are handled.
+//
+// In both modes, a handler returning logg.ErrStopLogEntry stops the entry
+// from being passed to the handlers after it.
+type Mode int
+
+const (
+	// FailFast stops at the first error and returns it,
+	// so the handlers after the failing one will not see the entry.
+	FailFast Mode = iota
+
+	// BestEffort passes the entry to all handlers and returns the errors joined
+	// (see errors.Join), so one failing handler does not affect the others.
+	// If a handler returns logg.ErrStopLogEntry, the errors from the handlers
+	// before it are returned, or logg.ErrStopLogEntry if there were none.
+	BestEffort
+)
+
 // Handler implementation.
 type Handler struct {
 	Handlers []logg.Handler
+	Mode     Mode
 }
 
-// New handler.
+// New handler in FailFast mode.
 func New(h ...logg.Handler) *Handler {
+	return NewWithMode(FailFast, h...)
+}
+
+// NewWithMode creates a new handler in the given mode.
+func NewWithMode(mode Mode, h ...logg.Handler) *Handler {
 	return &Handler{
 		Handlers: h,
+		Mode:     mode,
 	}
 }
 
 // HandleLog implements logg.Handler.
 func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	if h.Mode == BestEffort {
+		return h.handleLogBestEffort(ctx, e)
+	}
+
 	for _, handler := range h.Handlers {
-		// TODO(tj): maybe just write to stderr here, definitely not ideal
-		// to miss out logging to a more critical handler if something
-		// goes wrong
-		if err := handler.HandleLog(e); err != nil {
+		if err := logg.HandleLogContext(ctx, handler, e); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+func (h *Handler) handleLogBestEffort(ctx context.Context, e *logg.Entry) error {
+	var errs []error
+	for _, handler := range h.Handlers {
+		err := logg.HandleLogContext(ctx, handler, e)
+		if err == nil {
+			continue
+		}
+		if err == logg.ErrStopLogEntry {
+			if len(errs) == 0 {
+				return err
+			}
+			break
+		}
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Flush implements logg.Flusher by flushing all handlers.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.FlushAll(ctx, h.Handlers...)
+}
+
+// Close implements logg.Closer by closing all handlers.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.CloseAll(ctx, h.Handlers...)
+}
@@ -1,9 +1,13 @@
 package multi_test
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/level"
 	"github.com/bep/logg/handlers/memory"
 	"github.com/bep/logg/handlers/multi"
 	qt "github.com/frankban/quicktest"
@@ -74,3 +78,115 @@ func TestStopEntry(t *testing.T) {
 
 	qt.Assert(t, b.Entries, qt.HasLen, 2)
 }
+
+func TestMultiModes(t *testing.T) {
+	errFail := errors.New("fail")
+	failing := logg.HandlerFunc(func(e *logg.Entry) error {
+		if strings.HasPrefix(e.Message, "fail") {
+			return errFail
+		}
+		return nil
+	})
+	stopping := logg.HandlerFunc(func(e *logg.Entry) error {
+		if strings.HasSuffix(e.Message, "stop") {
+			return logg.ErrStopLogEntry
+		}
+		return nil
+	})
+
+	for _, test := range []struct {
+		name    string
+		mode    multi.Mode
+		message string
+		err     error
+		seen    []int
+	}{
+		{"FailFast ok", multi.FailFast, "ok", nil, []int{1, 1, 1}},
+		{"FailFast fail", multi.FailFast, "fail", errFail, []int{1, 0, 0}},
+		{"FailFast stop", multi.FailFast, "stop", logg.ErrStopLogEntry, []int{1, 1, 0}},
+		{"FailFast fail and stop", multi.FailFast, "fail+stop", errFail, []int{1, 0, 0}},
+		{"BestEffort ok", multi.BestEffort, "ok", nil, []int{1, 1, 1}},
+		{"BestEffort fail", multi.BestEffort, "fail", errFail, []int{1, 1, 1}},
+		{"BestEffort stop", multi.BestEffort, "stop", logg.ErrStopLogEntry, []int{1, 1, 0}},
+		{"BestEffort fail and stop", multi.BestEffort, "fail+stop", errFail, []int{1, 1, 0}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a, b, c := memory.New(), memory.New(), memory.New()
+			h := multi.NewWithMode(test.mode, a, failing, b, stopping, c)
+
+			err := h.HandleLog(&logg.Entry{Level: logg.LevelInfo, Message: test.message})
+			switch test.err {
+			case nil, logg.ErrStopLogEntry:
+				// The logger only treats ErrStopLogEntry itself as a non-error.
+				qt.Assert(t, err, qt.Equals, test.err)
+			default:
+				qt.Assert(t, err, qt.ErrorIs, test.err)
+			}
+			qt.Assert(t, []int{len(a.Entries), len(b.Entries), len(c.Entries)}, qt.DeepEquals, test.seen)
+		})
+	}
+}
+
+type ctxKey string
+
+func TestMultiContext(t *testing.T) {
+	var values []any
+	a := contextHandler(func(ctx context.Context, e *logg.Entry) error {
+		values = append(values, ctx.Value(ctxKey("trace")))
+		return nil
+	})
+	b := memory.New()
+
+	l := logg.New(
+		logg.Options{
+			Level:   logg.LevelInfo,
+			Handler: multi.New(b, a),
+		})
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t1")
+	l.WithLevel(logg.LevelInfo).WithContext(ctx).Log(logg.String("text"))
+
+	qt.Assert(t, b.Entries, qt.HasLen, 1)
+	qt.Assert(t, values, qt.DeepEquals, []any{"t1"})
+}
+
+func TestMultiFlushClose(t *testing.T) {
+	errFlush := errors.New("flush failed")
+	a := &lifecycleHandler{err: errFlush}
+	b := &lifecycleHandler{}
+	h := multi.New(a, level.New(b, logg.LevelError), memory.New())
+
+	qt.Assert(t, h.Flush(context.Background()), qt.ErrorIs, errFlush)
+	qt.Assert(t, h.Close(context.Background()), qt.ErrorIs, errFlush)
+	qt.Assert(t, a.calls, qt.DeepEquals, []string{"flush", "close"})
+	qt.Assert(t, b.calls, qt.DeepEquals, []string{"flush", "close"})
+}
+
+type lifecycleHandler struct {
+	calls []string
+	err   error
+}
+
+func (h *lifecycleHandler) HandleLog(e *logg.Entry) error {
+	return nil
+}
+
+func (h *lifecycleHandler) Flush(ctx context.Context) error {
+	h.calls = append(h.calls, "flush")
+	return h.err
+}
+
+func (h *lifecycleHandler) Close(ctx context.Context) error {
+	h.calls = append(h.calls, "close")
+	return h.err
+}
+
+type contextHandler func(ctx context.Context, e *logg.Entry) error
+
+func (h contextHandler) HandleLog(e *logg.Entry) error {
+	panic("HandleLogContext should be used")
+}
+
+func (h contextHandler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	return h(ctx, e)
+}
@@ -0,0 +1,314 @@
+// Package pii implements a handler which scans log messages and field values
+// for personally identifiable information and other sensitive content,
+// e.g. email addresses and credit card numbers.
+package pii
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// Detector detects one kind of sensitive content.
+type Detector struct {
+	// Name of the detector, e.g. "email".
+	// Matches are replaced with the name in angle brackets, e.g. "<email>".
+	Name string
+
+	// Regexp matches the candidates.
+	Regexp *regexp.Regexp
+
+	// Prefilter, if set, is a fast check reporting whether Regexp may match s,
+	// used to avoid running Regexp on most strings.
+	Prefilter func(s string) bool
+
+	// Valid, if set, is called to confirm each candidate, e.g. with a checksum.
+	Valid func(s string) bool
+}
+
+// The built-in detectors.
+var (
+	Email = Detector{
+		Name:      "email",
+		Regexp:    regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Prefilter: contains("@"),
+	}
+
+	IPv4 = Detector{
+		Name:      "ipv4",
+		Regexp:    regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+		Prefilter: contains("."),
+	}
+
+	IPv6 = Detector{
+		Name:      "ipv6",
+		Regexp:    regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}`),
+		Prefilter: contains(":"),
+		Valid: func(s string) bool {
+			addr, err := netip.ParseAddr(s)
+			return err == nil && addr.Is6() && !addr.IsUnspecified()
+		},
+	}
+
+	// CardNumber matches Luhn-valid numbers of 13 to 19 digits, optionally
+	// grouped with spaces or dashes.
+	CardNumber = Detector{
+		Name:      "card",
+		Regexp:    regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Prefilter: minDigits(13),
+		Valid:     luhn,
+	}
+
+	JWT = Detector{
+		Name:      "jwt",
+		Regexp:    regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+		Prefilter: contains("eyJ"),
+	}
+
+	// AWSAccessKey matches AWS access key IDs.
+	AWSAccessKey = Detector{
+		Name:      "aws_key",
+		Regexp:    regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`),
+		Prefilter: contains("AKIA", "ASIA", "AGPA", "AIDA", "AROA", "ANPA", "ANVA", "AIPA"),
+	}
+)
+
+// DefaultDetectors is the list of detectors used if none are set in Options.
+var DefaultDetectors = []Detector{JWT, Email, CardNumber, IPv4, IPv6, AWSAccessKey}
+
+// Options holds options for the pii handler.
+type Options struct {
+	// Detectors to use, in order.
+	// Default is DefaultDetectors.
+	Detectors []Detector
+
+	// ReportOnly, if set, only counts the matches, see Matches,
+	// and passes on the entries unchanged.
+	ReportOnly bool
+}
+
+// Handler implementation.
+//
+// The message and the string, error and fmt.Stringer field values of the
+// entries are scanned. Error and fmt.Stringer values with matches are
+// replaced by their string form with the matches replaced.
+// The entry is not modified. If anything is replaced, a copy of the entry is
+// passed on to the wrapped handler.
+type Handler struct {
+	opts    Options
+	handler logg.Handler
+
+	counts []atomic.Uint64 // Per detector.
+}
+
+// New handler scanning the entries passed to h.
+func New(h logg.Handler, opts Options) *Handler {
+	if opts.Detectors == nil {
+		opts.Detectors = DefaultDetectors
+	}
+	return &Handler{
+		opts:    opts,
+		handler: h,
+		counts:  make([]atomic.Uint64, len(opts.Detectors)),
+	}
+}
+
+// Matches returns the number of matches per detector name.
+func (h *Handler) Matches() map[string]uint64 {
+	m := make(map[string]uint64, len(h.opts.Detectors))
+	for i, d := range h.opts.Detectors {
+		m[d.Name] += h.counts[i].Load()
+	}
+	return m
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	var x *logg.Entry
+
+	if msg, changed := h.scan(e.Message); changed {
+		x = copyEntry(e)
+		x.Message = msg
+	}
+
+	if fields, changed := h.scanFields(e.Fields); changed {
+		if x == nil {
+			x = copyEntry(e)
+		}
+		x.Fields = fields
+	}
+
+	if x == nil {
+		return logg.HandleLogContext(ctx, h.handler, e)
+	}
+
+	return logg.HandleLogContext(ctx, h.handler, x)
+}
+
+// Flush implements logg.Flusher by flushing the wrapped handler.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.Flush(ctx, h.handler)
+}
+
+// Close implements logg.Closer by closing the wrapped handler.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.Close(ctx, h.handler)
+}
+
+// scanFields scans the string, error and fmt.Stringer values in fields,
+// including the values in groups, and returns a copy of fields with the
+// matches replaced and whether any was changed. The fields are left untouched.
+func (h *Handler) scanFields(fields logg.Fields) (logg.Fields, bool) {
+	var scanned logg.Fields
+	for i, f := range fields {
+		var s string
+		switch {
+		case f.Kind() == logg.KindGroup:
+			group, changed := h.scanFields(f.Group())
+			if !changed {
+				continue
+			}
+			if scanned == nil {
+				scanned = append(logg.Fields(nil), fields...)
+			}
+			scanned[i] = logg.Group(f.Name, group...)
+			continue
+		case f.Kind() == logg.KindString:
+			s = f.Str()
+		case f.Kind() == logg.KindAny:
+			switch v := f.Value.(type) {
+			case string:
+				s = v
+			case error:
+				s = v.Error()
+			case fmt.Stringer:
+				s = v.String()
+			default:
+				continue
+			}
+		default:
+			continue
+		}
+		if s, changed := h.scan(s); changed {
+			if scanned == nil {
+				scanned = append(logg.Fields(nil), fields...)
+			}
+			scanned[i] = logg.Str(f.Name, s)
+		}
+	}
+	return scanned, scanned != nil
+}
+
+// scan counts the matches in s and, unless ReportOnly is set, returns s with
+// the matches replaced and whether it was changed.
+func (h *Handler) scan(s string) (string, bool) {
+	changed := false
+	for i, d := range h.opts.Detectors {
+		if d.Prefilter != nil && !d.Prefilter(s) {
+			continue
+		}
+		locs := d.Regexp.FindAllStringIndex(s, -1)
+		if locs == nil {
+			continue
+		}
+
+		var (
+			b    strings.Builder
+			last int
+		)
+		for _, loc := range locs {
+			if d.Valid != nil && !d.Valid(s[loc[0]:loc[1]]) {
+				continue
+			}
+			h.counts[i].Add(1)
+			if h.opts.ReportOnly {
+				continue
+			}
+			b.WriteString(s[last:loc[0]])
+			b.WriteByte('<')
+			b.WriteString(d.Name)
+			b.WriteByte('>')
+			last = loc[1]
+		}
+		if last > 0 {
+			b.WriteString(s[last:])
+			s = b.String()
+			changed = true
+		}
+	}
+	return s, changed
+}
+
+// copyEntry returns a copy of e with a copy of the fields.
+func copyEntry(e *logg.Entry) *logg.Entry {
+	x := *e
+	x.Fields = append(logg.Fields(nil), e.Fields...)
+	return &x
+}
+
+// contains returns a prefilter reporting whether s contains any of substrs.
+func contains(substrs ...string) func(s string) bool {
+	return func(s string) bool {
+		for _, sub := range substrs {
+			if strings.Contains(s, sub) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// minDigits returns a prefilter reporting whether s has at least n digits.
+func minDigits(n int) func(s string) bool {
+	return func(s string) bool {
+		var count int
+		for i := 0; i < len(s); i++ {
+			if s[i] >= '0' && s[i] <= '9' {
+				count++
+				if count >= n {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum.
+// Spaces and dashes are ignored.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
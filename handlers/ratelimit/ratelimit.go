@@ -0,0 +1,246 @@
+// Package ratelimit implements a handler which limits the number of
+// log entries passed on per level using token buckets.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bep/clocks"
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// Limit is the rate limit for a level.
+type Limit struct {
+	// Rate is the number of entries allowed per second.
+	Rate float64
+
+	// Burst is the maximum number of entries allowed at once.
+	// Default is Rate rounded up, minimum 1.
+	Burst int
+}
+
+// Options holds options for the ratelimit handler.
+type Options struct {
+	// Limits holds the rate limit per level.
+	// Levels without a limit are not rate limited.
+	Limits map[logg.Level]Limit
+
+	// SummaryInterval is the minimum interval between summary entries
+	// reporting the number of dropped entries.
+	// The summary is logged before the next entry passed on after the
+	// interval has passed, or from a timer when the interval has passed
+	// if no entries are passed on. Errors from the wrapped handler when
+	// logging the summary from the timer are ignored.
+	// Default is 10 seconds.
+	SummaryInterval time.Duration
+
+	// SummaryLevel is the level of the summary entries.
+	// Summary entries are never rate limited.
+	// Default is logg.LevelWarn.
+	SummaryLevel logg.Level
+
+	// Clock is the clock used for the token buckets.
+	// If not set, the system clock is used.
+	Clock logg.Clock
+}
+
+// Handler implementation.
+type Handler struct {
+	opts    Options
+	handler logg.Handler
+
+	mu             sync.Mutex
+	buckets        map[logg.Level]*bucket
+	dropped        uint64
+	droppedByLevel map[logg.Level]uint64
+	unreported     uint64
+	lastSummary    time.Time
+	timer          *time.Timer
+	gen            int
+	closed         bool
+}
+
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) allow(now time.Time) bool {
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// New handler rate limiting the entries passed to h.
+func New(h logg.Handler, opts Options) *Handler {
+	if opts.SummaryInterval <= 0 {
+		opts.SummaryInterval = 10 * time.Second
+	}
+	if opts.SummaryLevel == logg.LevelInvalid {
+		opts.SummaryLevel = logg.LevelWarn
+	}
+	if opts.Clock == nil {
+		opts.Clock = clocks.System()
+	}
+
+	buckets := make(map[logg.Level]*bucket, len(opts.Limits))
+	for level, limit := range opts.Limits {
+		burst := float64(limit.Burst)
+		if burst <= 0 {
+			burst = math.Max(1, math.Ceil(limit.Rate))
+		}
+		buckets[level] = &bucket{rate: limit.Rate, burst: burst, tokens: burst}
+	}
+
+	return &Handler{
+		opts:           opts,
+		handler:        h,
+		buckets:        buckets,
+		droppedByLevel: make(map[logg.Level]uint64),
+		lastSummary:    opts.Clock.Now(),
+	}
+}
+
+// Dropped returns the total number of entries dropped.
+func (h *Handler) Dropped() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.dropped
+}
+
+// DroppedByLevel returns the number of entries dropped per level.
+func (h *Handler) DroppedByLevel() map[logg.Level]uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return maps.Clone(h.droppedByLevel)
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	now := h.opts.Clock.Now()
+
+	h.mu.Lock()
+	if b, found := h.buckets[e.Level]; found && !b.allow(now) {
+		h.dropped++
+		h.droppedByLevel[e.Level]++
+		h.unreported++
+		if h.timer == nil && !h.closed {
+			gen := h.gen
+			h.timer = time.AfterFunc(max(0, h.opts.SummaryInterval-now.Sub(h.lastSummary)), func() { h.timeout(gen) })
+		}
+		h.mu.Unlock()
+		return nil
+	}
+	var unreported uint64
+	if h.unreported > 0 && now.Sub(h.lastSummary) >= h.opts.SummaryInterval {
+		unreported = h.reportLocked(now)
+	}
+	h.mu.Unlock()
+
+	if unreported > 0 {
+		// The summary isn't related to e, so it doesn't get its context.
+		if err := logg.HandleLogContext(context.Background(), h.handler, h.summary(now, unreported)); err != nil {
+			return err
+		}
+	}
+
+	return logg.HandleLogContext(ctx, h.handler, e)
+}
+
+// Flush implements logg.Flusher.
+// Any unreported dropped entries are reported in a summary entry before
+// the wrapped handler is flushed.
+func (h *Handler) Flush(ctx context.Context) error {
+	return errors.Join(h.flushSummary(ctx), logg.Flush(ctx, h.handler))
+}
+
+// Close implements logg.Closer.
+// Any unreported dropped entries are reported in a summary entry before
+// the wrapped handler is closed.
+func (h *Handler) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+	return errors.Join(h.flushSummary(ctx), logg.Close(ctx, h.handler))
+}
+
+func (h *Handler) flushSummary(ctx context.Context) error {
+	now := h.opts.Clock.Now()
+
+	h.mu.Lock()
+	unreported := h.reportLocked(now)
+	h.mu.Unlock()
+
+	if unreported == 0 {
+		return nil
+	}
+
+	return logg.HandleLogContext(ctx, h.handler, h.summary(now, unreported))
+}
+
+func (h *Handler) timeout(gen int) {
+	now := h.opts.Clock.Now()
+
+	h.mu.Lock()
+	if gen != h.gen {
+		// Reported by other means.
+		h.mu.Unlock()
+		return
+	}
+	unreported := h.reportLocked(now)
+	h.mu.Unlock()
+
+	if unreported > 0 {
+		logg.HandleLogContext(context.Background(), h.handler, h.summary(now, unreported))
+	}
+}
+
+// reportLocked returns the number of unreported dropped entries, resets it
+// and stops the summary timer.
+func (h *Handler) reportLocked(now time.Time) uint64 {
+	unreported := h.unreported
+	h.unreported = 0
+	h.lastSummary = now
+	h.gen++
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	return unreported
+}
+
+// summary creates a summary entry.
+func (h *Handler) summary(now time.Time, dropped uint64) *logg.Entry {
+	return &logg.Entry{
+		Level:     h.opts.SummaryLevel,
+		Timestamp: now,
+		Message:   fmt.Sprintf("dropped %d entries", dropped),
+		Fields:    logg.Fields{logg.Int64("dropped", int64(dropped))},
+	}
+}
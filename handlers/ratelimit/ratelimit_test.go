@@ -0,0 +1,155 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/memory"
+	"github.com/bep/logg/handlers/ratelimit"
+	qt "github.com/frankban/quicktest"
+)
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.t
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := &testClock{t: time.Date(2022, 8, 12, 10, 0, 0, 0, time.UTC)}
+	h := memory.New()
+	rl := ratelimit.New(h, ratelimit.Options{
+		Limits: map[logg.Level]ratelimit.Limit{
+			logg.LevelInfo: {Rate: 2, Burst: 3},
+		},
+		SummaryInterval: 5 * time.Second,
+		Clock:           clock,
+	})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: rl, AddSource: true})
+	info := l.Named("web").WithLevel(logg.LevelInfo)
+	errorl := l.WithLevel(logg.LevelError)
+
+	for range 10 {
+		info.Log(logg.String("info"))
+		errorl.Log(logg.String("error"))
+	}
+
+	count := func(level logg.Level) int {
+		var n int
+		for _, e := range h.Entries {
+			if e.Level == level {
+				n++
+			}
+		}
+		return n
+	}
+
+	qt.Assert(t, count(logg.LevelInfo), qt.Equals, 3)
+	qt.Assert(t, count(logg.LevelError), qt.Equals, 10)
+	qt.Assert(t, rl.Dropped(), qt.Equals, uint64(7))
+	qt.Assert(t, rl.DroppedByLevel(), qt.DeepEquals, map[logg.Level]uint64{logg.LevelInfo: 7})
+
+	// Refill 2 tokens.
+	clock.t = clock.t.Add(time.Second)
+	for range 3 {
+		info.Log(logg.String("info"))
+	}
+	qt.Assert(t, count(logg.LevelInfo), qt.Equals, 5)
+	qt.Assert(t, rl.Dropped(), qt.Equals, uint64(8))
+
+	// The summary is logged before the next entry after SummaryInterval.
+	clock.t = clock.t.Add(5 * time.Second)
+	n := len(h.Entries)
+	info.Log(logg.String("info"))
+	qt.Assert(t, h.Entries, qt.HasLen, n+2)
+	summary := h.Entries[n]
+	qt.Assert(t, summary.Level, qt.Equals, logg.LevelWarn)
+	qt.Assert(t, summary.Message, qt.Equals, "dropped 8 entries")
+	qt.Assert(t, summary.Timestamp, qt.Equals, clock.t)
+	qt.Assert(t, summary.Fields, qt.DeepEquals, logg.Fields{logg.Int64("dropped", 8)})
+	// The summary isn't attributed to the entry that triggered it.
+	qt.Assert(t, summary.LoggerName, qt.Equals, "")
+	qt.Assert(t, summary.Source, qt.Equals, logg.Frame{})
+	qt.Assert(t, h.Entries[n+1].Message, qt.Equals, "info")
+	qt.Assert(t, h.Entries[n+1].LoggerName, qt.Equals, "web")
+
+	// Nothing more to report.
+	clock.t = clock.t.Add(5 * time.Second)
+	info.Log(logg.String("info"))
+	qt.Assert(t, h.Entries, qt.HasLen, n+3)
+}
+
+func TestRateLimitFlush(t *testing.T) {
+	clock := &testClock{t: time.Date(2022, 8, 12, 10, 0, 0, 0, time.UTC)}
+	h := memory.New()
+	rl := ratelimit.New(h, ratelimit.Options{
+		Limits: map[logg.Level]ratelimit.Limit{
+			logg.LevelInfo: {Rate: 1},
+		},
+		Clock: clock,
+	})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: rl})
+
+	for range 3 {
+		l.WithLevel(logg.LevelInfo).Log(logg.String("info"))
+	}
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+
+	qt.Assert(t, l.Flush(context.Background()), qt.IsNil)
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+	qt.Assert(t, h.Entries[1].Message, qt.Equals, "dropped 2 entries")
+	qt.Assert(t, h.Entries[1].Timestamp, qt.Equals, clock.t)
+
+	// Nothing more to report.
+	qt.Assert(t, l.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+}
+
+func TestRateLimitSummaryTimer(t *testing.T) {
+	entries := make(chan *logg.Entry, 10)
+	h := logg.HandlerFunc(func(e *logg.Entry) error {
+		entries <- e.Clone()
+		return nil
+	})
+	rl := ratelimit.New(h, ratelimit.Options{
+		Limits: map[logg.Level]ratelimit.Limit{
+			logg.LevelInfo: {Rate: 1},
+		},
+		SummaryInterval: 20 * time.Millisecond,
+	})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: rl})
+
+	// Nothing is logged after the entries are dropped.
+	for range 5 {
+		l.WithLevel(logg.LevelInfo).Log(logg.String("info"))
+	}
+	qt.Assert(t, (<-entries).Message, qt.Equals, "info")
+
+	select {
+	case e := <-entries:
+		qt.Assert(t, e.Message, qt.Equals, "dropped 4 entries")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no summary")
+	}
+
+	qt.Assert(t, l.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, entries, qt.HasLen, 0)
+}
+
+func BenchmarkRateLimit(b *testing.B) {
+	h := ratelimit.New(logg.HandlerFunc(func(e *logg.Entry) error { return nil }), ratelimit.Options{
+		Limits: map[logg.Level]ratelimit.Limit{
+			logg.LevelInfo: {Rate: 1000},
+		},
+	})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+	info := l.WithLevel(logg.LevelInfo)
+
+	for b.Loop() {
+		info.Log(logg.String("hot"))
+	}
+}
@@ -0,0 +1,248 @@
+// Package redact implements a handler which masks or removes fields
+// with sensitive values, e.g. passwords and tokens.
+package redact
+
+import (
+	"context"
+	"maps"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// DefaultKeys is a list of commonly used names for fields with sensitive values.
+var DefaultKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"*token",
+	"authorization",
+	"cookie",
+	"api_key",
+	"apikey",
+}
+
+// Options holds options for the redact handler.
+type Options struct {
+	// Keys holds the field names to redact, matched case-insensitively.
+	// A key may be a glob pattern as supported by path.Match, e.g. "*token".
+	//
+	// Nested keys are matched against the dot separated path to the value,
+	// e.g. "http.headers.authorization" for a field named "http.headers" with
+	// a map value with an "authorization" key. Keys without a dot match the
+	// last element of the path only, so "authorization" matches the above.
+	// Map values (map[string]any and map[string]string) and logg.Fields
+	// values are searched for nested keys.
+	//
+	// Default is DefaultKeys.
+	Keys []string
+
+	// Regexps holds regular expressions matched against the full path to
+	// the value, see Keys. Use the (?i) flag for case-insensitive matching.
+	Regexps []*regexp.Regexp
+
+	// Remove, if set, removes the matching fields instead of masking them.
+	Remove bool
+
+	// Mask is the value set for the matching fields.
+	// Default is "***".
+	Mask string
+}
+
+// Handler implementation.
+//
+// The entry is not modified. If any field matches, a copy of the entry
+// with new fields is passed on to the wrapped handler.
+type Handler struct {
+	opts    Options
+	handler logg.Handler
+
+	keys    map[string]bool // Lower case keys without glob patterns.
+	globs   []string        // Lower case glob patterns.
+	regexps []*regexp.Regexp
+}
+
+// New handler redacting the entries passed to h.
+func New(h logg.Handler, opts Options) *Handler {
+	if opts.Keys == nil {
+		opts.Keys = DefaultKeys
+	}
+	if opts.Mask == "" {
+		opts.Mask = "***"
+	}
+
+	r := &Handler{
+		opts:    opts,
+		handler: h,
+		keys:    make(map[string]bool),
+		regexps: opts.Regexps,
+	}
+	for _, k := range opts.Keys {
+		k = strings.ToLower(k)
+		if strings.ContainsAny(k, `*?[\`) {
+			r.globs = append(r.globs, k)
+		} else {
+			r.keys[k] = true
+		}
+	}
+
+	return r
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	var fields logg.Fields
+	for i, f := range e.Fields {
+		v, redacted, remove := h.redact(f.Name, f.Value)
+		if !redacted {
+			if fields != nil {
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if fields == nil {
+			// Copy on write, the entry's fields must not be modified.
+			fields = make(logg.Fields, i, len(e.Fields))
+			copy(fields, e.Fields[:i])
+		}
+		if !remove {
+			fields = append(fields, withValue(f, v))
+		}
+	}
+
+	if fields == nil {
+		return logg.HandleLogContext(ctx, h.handler, e)
+	}
+
+	x := *e
+	x.Fields = fields
+	return logg.HandleLogContext(ctx, h.handler, &x)
+}
+
+// Flush implements logg.Flusher by flushing the wrapped handler.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.Flush(ctx, h.handler)
+}
+
+// Close implements logg.Closer by closing the wrapped handler.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.Close(ctx, h.handler)
+}
+
+// redact returns the redacted value of v and whether it was redacted,
+// and if so, whether it should be removed.
+// Nested values are redacted in copies.
+func (h *Handler) redact(p string, v any) (any, bool, bool) {
+	if h.match(p) {
+		return h.opts.Mask, true, h.opts.Remove
+	}
+
+	switch vv := v.(type) {
+	case map[string]any:
+		var m map[string]any
+		for k, mv := range vv {
+			rv, redacted, remove := h.redact(p+"."+k, mv)
+			if !redacted {
+				continue
+			}
+			if m == nil {
+				m = maps.Clone(vv)
+			}
+			if remove {
+				delete(m, k)
+			} else {
+				m[k] = rv
+			}
+		}
+		if m != nil {
+			return m, true, false
+		}
+	case map[string]string:
+		var m map[string]string
+		for k := range vv {
+			if !h.match(p + "." + k) {
+				continue
+			}
+			if m == nil {
+				m = maps.Clone(vv)
+			}
+			if h.opts.Remove {
+				delete(m, k)
+			} else {
+				m[k] = h.opts.Mask
+			}
+		}
+		if m != nil {
+			return m, true, false
+		}
+	case logg.Fields:
+		var fields logg.Fields
+		for i, f := range vv {
+			rv, redacted, remove := h.redact(p+"."+f.Name, f.Value)
+			if !redacted {
+				if fields != nil {
+					fields = append(fields, f)
+				}
+				continue
+			}
+			if fields == nil {
+				fields = make(logg.Fields, i, len(vv))
+				copy(fields, vv[:i])
+			}
+			if !remove {
+				fields = append(fields, withValue(f, rv))
+			}
+		}
+		if fields != nil {
+			return fields, true, false
+		}
+	}
+
+	return v, false, false
+}
+
+// withValue returns f with the redacted value v.
+func withValue(f logg.Field, v any) logg.Field {
+	if fields, ok := v.(logg.Fields); ok && f.Kind() == logg.KindGroup {
+		return logg.Group(f.Name, fields...)
+	}
+	return logg.Field{Name: f.Name, Value: v}
+}
+
+// match reports whether the value at path p should be redacted.
+func (h *Handler) match(p string) bool {
+	lp := strings.ToLower(p)
+	leaf := lp[strings.LastIndexByte(lp, '.')+1:]
+	if h.keys[lp] || h.keys[leaf] {
+		return true
+	}
+	for _, g := range h.globs {
+		s := leaf
+		if strings.Contains(g, ".") {
+			s = lp
+		}
+		if ok, _ := path.Match(g, s); ok {
+			return true
+		}
+	}
+	for _, re := range h.regexps {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
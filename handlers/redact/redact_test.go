@@ -0,0 +1,100 @@
+package redact_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/memory"
+	"github.com/bep/logg/handlers/multi"
+	"github.com/bep/logg/handlers/redact"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRedact(t *testing.T) {
+	h := memory.New()
+	var original logg.Fields
+	capture := logg.HandlerFunc(func(e *logg.Entry) error {
+		original = append(logg.Fields(nil), e.Fields...)
+		return nil
+	})
+	l := logg.New(logg.Options{
+		Level:   logg.LevelInfo,
+		Handler: multi.New(redact.New(h, redact.Options{}), capture),
+	})
+
+	headers := map[string]any{"Authorization": "Bearer abc", "Accept": "*/*"}
+	l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{
+		logg.Str("user", "tj"),
+		logg.Str("Password", "hunter2"),
+		{Name: "http.headers", Value: headers},
+		logg.Str("http.req.Cookie", "session=1"),
+		logg.Str("refresh_token", "xyz"),
+	}).Log(logg.String("login"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{
+		logg.Str("user", "tj"),
+		{Name: "Password", Value: "***"},
+		{Name: "http.headers", Value: map[string]any{"Authorization": "***", "Accept": "*/*"}},
+		{Name: "http.req.Cookie", Value: "***"},
+		{Name: "refresh_token", Value: "***"},
+	})
+
+	// The original entry and values are not modified.
+	qt.Assert(t, original[1], qt.DeepEquals, logg.Str("Password", "hunter2"))
+	qt.Assert(t, headers["Authorization"], qt.Equals, "Bearer abc")
+}
+
+func TestRedactOptions(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{
+		Level: logg.LevelInfo,
+		Handler: redact.New(h, redact.Options{
+			Keys:    []string{"card.number", "ssn"},
+			Regexps: []*regexp.Regexp{regexp.MustCompile(`(?i)^x-.*-key$`)},
+			Remove:  true,
+		}),
+	})
+
+	l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{
+		logg.Group("card", logg.Str("number", "4111"), logg.Str("brand", "visa")),
+		{Name: "number", Value: 42},
+		{Name: "meta", Value: map[string]string{"SSN": "123", "name": "tj"}},
+		logg.Str("X-Api-Key", "abc"),
+		logg.Str("password", "not in Keys"),
+	}).Log(logg.String("payment"))
+
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{
+		logg.Group("card", logg.Str("brand", "visa")),
+		{Name: "number", Value: 42},
+		{Name: "meta", Value: map[string]string{"name": "tj"}},
+		logg.Str("password", "not in Keys"),
+	})
+}
+
+func TestRedactNoMatch(t *testing.T) {
+	var fields logg.Fields
+	h := redact.New(logg.HandlerFunc(func(e *logg.Entry) error {
+		fields = e.Fields
+		return nil
+	}), redact.Options{Mask: "[redacted]"})
+
+	e := &logg.Entry{Fields: logg.Fields{logg.Str("user", "tj")}}
+	qt.Assert(t, h.HandleLog(e), qt.IsNil)
+	qt.Assert(t, &fields[0], qt.Equals, &e.Fields[0])
+
+	e.Fields = append(e.Fields, logg.Str("secret", "s"))
+	qt.Assert(t, h.HandleLog(e), qt.IsNil)
+	qt.Assert(t, fields, qt.DeepEquals, logg.Fields{logg.Str("user", "tj"), {Name: "secret", Value: "[redacted]"}})
+}
+
+func BenchmarkRedact(b *testing.B) {
+	h := redact.New(logg.HandlerFunc(func(e *logg.Entry) error { return nil }), redact.Options{})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+	info := l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{logg.Str("user", "tj"), logg.Int("id", 123), logg.Str("method", "GET")})
+
+	for b.Loop() {
+		info.Log(logg.String("hello"))
+	}
+}
@@ -0,0 +1,290 @@
+// Package route implements a handler which dispatches log entries to
+// handlers based on predicates.
+package route
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// Predicate reports whether e matches.
+type Predicate func(e *logg.Entry) bool
+
+// Route is a handler with a predicate deciding which entries it handles.
+type Route struct {
+	Match   Predicate
+	Handler logg.Handler
+}
+
+// Mode decides how many routes an entry is passed to.
+type Mode int
+
+const (
+	// FirstMatch passes the entry to the first matching route only.
+	FirstMatch Mode = iota
+
+	// AllMatches passes the entry to all matching routes, in order.
+	// A route returning logg.ErrStopLogEntry stops the entry from
+	// being passed to the routes after it.
+	AllMatches
+)
+
+// Options holds options for the route handler.
+type Options struct {
+	// Mode decides how many routes an entry is passed to.
+	// Default is FirstMatch.
+	Mode Mode
+
+	// Default, if set, handles the entries not matching any route.
+	// If not set, those entries are dropped.
+	Default logg.Handler
+}
+
+// Handler implementation.
+type Handler struct {
+	opts   Options
+	routes []Route
+}
+
+// New handler dispatching entries to routes, checked in order.
+func New(routes []Route, opts Options) *Handler {
+	return &Handler{
+		opts:   opts,
+		routes: routes,
+	}
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+// In AllMatches mode, the errors from the routes are joined.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	var (
+		matched bool
+		errs    []error
+	)
+	for _, r := range h.routes {
+		if !r.Match(e) {
+			continue
+		}
+		matched = true
+		err := logg.HandleLogContext(ctx, r.Handler, e)
+		if h.opts.Mode == FirstMatch || err == logg.ErrStopLogEntry {
+			if len(errs) == 0 {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !matched && h.opts.Default != nil {
+		return logg.HandleLogContext(ctx, h.opts.Default, e)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Flush implements logg.Flusher by flushing all route handlers and the default handler.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.FlushAll(ctx, h.handlers()...)
+}
+
+// Close implements logg.Closer by closing all route handlers and the default handler.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.CloseAll(ctx, h.handlers()...)
+}
+
+// handlers returns all handlers, with handlers used in more than one route only once.
+func (h *Handler) handlers() []logg.Handler {
+	all := make([]logg.Handler, 0, len(h.routes)+1)
+	for _, r := range h.routes {
+		all = append(all, r.Handler)
+	}
+	if h.opts.Default != nil {
+		all = append(all, h.opts.Default)
+	}
+
+	seen := make(map[logg.Handler]bool)
+	handlers := all[:0]
+	for _, handler := range all {
+		if reflect.TypeOf(handler).Comparable() {
+			if seen[handler] {
+				continue
+			}
+			seen[handler] = true
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers
+}
+
+// Levels matches entries with a level in the range [from, to].
+func Levels(from, to logg.Level) Predicate {
+	return func(e *logg.Entry) bool {
+		return e.Level >= from && e.Level <= to
+	}
+}
+
+// MinLevel matches entries with a level at or above level.
+func MinLevel(level logg.Level) Predicate {
+	return func(e *logg.Entry) bool {
+		return e.Level >= level
+	}
+}
+
+// HasField matches entries with a field with the given name.
+// Fields in groups are matched with dotted names, e.g. "http.status".
+func HasField(name string) Predicate {
+	return func(e *logg.Entry) bool {
+		_, found := e.Fields.Lookup(name)
+		return found
+	}
+}
+
+// FieldEquals matches entries with a field with the given name and value.
+// Values created with the typed constructors (e.g. logg.Int64) are
+// compared with their Any value, and integer and floating point values
+// are compared by value regardless of type, e.g. FieldEquals("status", 200)
+// matches logg.Int("status", 200).
+// Fields in groups are matched with dotted names, e.g. "http.status".
+func FieldEquals(name string, value any) Predicate {
+	want := logg.Any(name, value)
+	wi, wf, wIsFloat, wIsNumber := numberValue(want)
+	return func(e *logg.Entry) bool {
+		f, found := e.Fields.Lookup(name)
+		if !found {
+			return false
+		}
+		if wIsNumber {
+			i, fl, isFloat, ok := numberValue(f)
+			if !ok {
+				return false
+			}
+			if !isFloat && !wIsFloat {
+				return i == wi
+			}
+			a, b := fl, wf
+			if !isFloat {
+				a = float64(i)
+			}
+			if !wIsFloat {
+				b = float64(wi)
+			}
+			return a == b
+		}
+		return f.Equal(logg.Any(f.Name, value))
+	}
+}
+
+// numberValue returns f's value as an int64, or as a float64 if it's a
+// floating point value or an unsigned integer too large for an int64.
+func numberValue(f logg.Field) (i int64, fl float64, isFloat, ok bool) {
+	switch f.Kind() {
+	case logg.KindInt64:
+		return f.Int64(), 0, false, true
+	case logg.KindFloat64:
+		return 0, f.Float64(), true, true
+	case logg.KindAny:
+	default:
+		return 0, 0, false, false
+	}
+
+	switch v := f.Value.(type) {
+	case int:
+		return int64(v), 0, false, true
+	case int8:
+		return int64(v), 0, false, true
+	case int16:
+		return int64(v), 0, false, true
+	case int32:
+		return int64(v), 0, false, true
+	case int64:
+		return v, 0, false, true
+	case uint:
+		return uintValue(uint64(v))
+	case uint8:
+		return int64(v), 0, false, true
+	case uint16:
+		return int64(v), 0, false, true
+	case uint32:
+		return int64(v), 0, false, true
+	case uint64:
+		return uintValue(v)
+	case float32:
+		return 0, float64(v), true, true
+	case float64:
+		return 0, v, true, true
+	}
+	return 0, 0, false, false
+}
+
+func uintValue(v uint64) (int64, float64, bool, bool) {
+	if v > math.MaxInt64 {
+		return 0, float64(v), true, true
+	}
+	return int64(v), 0, false, true
+}
+
+// MessagePrefix matches entries with a message starting with prefix.
+func MessagePrefix(prefix string) Predicate {
+	return func(e *logg.Entry) bool {
+		return strings.HasPrefix(e.Message, prefix)
+	}
+}
+
+// MessageMatches matches entries with a message matching re.
+func MessageMatches(re *regexp.Regexp) Predicate {
+	return func(e *logg.Entry) bool {
+		return re.MatchString(e.Message)
+	}
+}
+
+// And matches entries matching all of preds.
+func And(preds ...Predicate) Predicate {
+	return func(e *logg.Entry) bool {
+		for _, p := range preds {
+			if !p(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches entries matching any of preds.
+func Or(preds ...Predicate) Predicate {
+	return func(e *logg.Entry) bool {
+		for _, p := range preds {
+			if p(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches entries not matching p.
+func Not(p Predicate) Predicate {
+	return func(e *logg.Entry) bool {
+		return !p(e)
+	}
+}
@@ -0,0 +1,148 @@
+package route_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/memory"
+	"github.com/bep/logg/handlers/route"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRoute(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		mode     route.Mode
+		expected [3]int
+	}{
+		{"FirstMatch", route.FirstMatch, [3]int{2, 2, 1}},
+		{"AllMatches", route.AllMatches, [3]int{2, 3, 1}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			stderr, access, main := memory.New(), memory.New(), memory.New()
+			h := route.New(
+				[]route.Route{
+					{Match: route.MinLevel(logg.LevelError), Handler: stderr},
+					{Match: route.FieldEquals("component", "http"), Handler: access},
+				},
+				route.Options{Mode: test.mode, Default: main},
+			)
+			l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+			http := l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{logg.Str("component", "http")})
+
+			http.Log(logg.String("GET /"))
+			http.WithLevel(logg.LevelError).Log(logg.String("GET /boom"))
+			http.WithField("component", "db").Log(logg.String("query"))
+			l.WithLevel(logg.LevelError).Log(logg.String("boom"))
+			http.Log(logg.String("GET /about"))
+
+			qt.Assert(t, [3]int{len(stderr.Entries), len(access.Entries), len(main.Entries)}, qt.Equals, test.expected)
+			qt.Assert(t, main.Entries[0].Message, qt.Equals, "query")
+		})
+	}
+}
+
+func TestRouteErrors(t *testing.T) {
+	errFail := errors.New("fail")
+	a, b := memory.New(), memory.New()
+	h := route.New(
+		[]route.Route{
+			{Match: route.MessagePrefix("fail"), Handler: logg.HandlerFunc(func(e *logg.Entry) error { return errFail })},
+			{Match: route.MessagePrefix("stop"), Handler: logg.HandlerFunc(func(e *logg.Entry) error { return logg.ErrStopLogEntry })},
+			{Match: route.HasField("user"), Handler: a},
+		},
+		route.Options{Mode: route.AllMatches, Default: b},
+	)
+
+	user := logg.Fields{logg.Str("user", "tj")}
+	qt.Assert(t, h.HandleLog(&logg.Entry{Message: "fail", Fields: user}), qt.ErrorIs, errFail)
+	qt.Assert(t, a.Entries, qt.HasLen, 1)
+	qt.Assert(t, h.HandleLog(&logg.Entry{Message: "stop", Fields: user}), qt.Equals, logg.ErrStopLogEntry)
+	qt.Assert(t, a.Entries, qt.HasLen, 1)
+	qt.Assert(t, h.HandleLog(&logg.Entry{Message: "hello"}), qt.IsNil)
+	qt.Assert(t, b.Entries, qt.HasLen, 1)
+
+	// Without a default route, unmatched entries are dropped.
+	h = route.New(nil, route.Options{})
+	qt.Assert(t, h.HandleLog(&logg.Entry{Message: "hello"}), qt.IsNil)
+}
+
+func TestPredicates(t *testing.T) {
+	e := &logg.Entry{
+		Level:   logg.LevelWarn,
+		Message: "GET /about",
+		Fields: logg.Fields{
+			logg.Int("status", 404),
+			logg.Str("method", "GET"),
+			logg.Int("status", 200),
+			logg.Group("http", logg.Group("res", logg.Int("status", 503))),
+		},
+	}
+
+	for _, test := range []struct {
+		name     string
+		pred     route.Predicate
+		expected bool
+	}{
+		{"Levels", route.Levels(logg.LevelInfo, logg.LevelWarn), true},
+		{"Levels below", route.Levels(logg.LevelError, logg.LevelError), false},
+		{"MinLevel", route.MinLevel(logg.LevelWarn), true},
+		{"MinLevel above", route.MinLevel(logg.LevelError), false},
+		{"HasField", route.HasField("method"), true},
+		{"HasField missing", route.HasField("user"), false},
+		{"FieldEquals", route.FieldEquals("method", "GET"), true},
+		{"FieldEquals last field wins", route.FieldEquals("status", int64(200)), true},
+		{"FieldEquals other value", route.FieldEquals("status", int64(404)), false},
+		{"FieldEquals int", route.FieldEquals("status", 200), true},
+		{"FieldEquals float", route.FieldEquals("status", 200.0), true},
+		{"FieldEquals uint8", route.FieldEquals("status", uint8(200)), true},
+		{"FieldEquals other type", route.FieldEquals("status", "200"), false},
+		{"HasField group", route.HasField("http.res"), true},
+		{"HasField in group", route.HasField("http.res.status"), true},
+		{"HasField in group missing", route.HasField("http.status"), false},
+		{"FieldEquals in group", route.FieldEquals("http.res.status", int64(503)), true},
+		{"MessagePrefix", route.MessagePrefix("GET "), true},
+		{"MessageMatches", route.MessageMatches(regexp.MustCompile(`^(GET|POST) /a`)), true},
+		{"MessageMatches no match", route.MessageMatches(regexp.MustCompile(`^POST`)), false},
+		{"And", route.And(route.HasField("method"), route.MinLevel(logg.LevelWarn)), true},
+		{"And one false", route.And(route.HasField("method"), route.MinLevel(logg.LevelError)), false},
+		{"Or", route.Or(route.HasField("user"), route.MinLevel(logg.LevelWarn)), true},
+		{"Not", route.Not(route.HasField("user")), true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			qt.Assert(t, test.pred(e), qt.Equals, test.expected)
+		})
+	}
+}
+
+func TestRouteFlushClose(t *testing.T) {
+	a := &closeCounter{}
+	h := route.New(
+		[]route.Route{
+			{Match: route.MinLevel(logg.LevelError), Handler: a},
+			{Match: route.HasField("user"), Handler: a},
+			{Match: route.HasField("id"), Handler: logg.HandlerFunc(func(e *logg.Entry) error { return nil })},
+		},
+		route.Options{Default: a},
+	)
+
+	qt.Assert(t, h.Flush(context.Background()), qt.IsNil)
+	qt.Assert(t, h.Close(context.Background()), qt.IsNil)
+	qt.Assert(t, a.closed, qt.Equals, 1)
+}
+
+type closeCounter struct {
+	closed int
+}
+
+func (h *closeCounter) HandleLog(e *logg.Entry) error {
+	return nil
+}
+
+func (h *closeCounter) Close(ctx context.Context) error {
+	h.closed++
+	return nil
+}
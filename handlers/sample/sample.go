@@ -0,0 +1,143 @@
+// Package sample implements a handler which samples log entries
+// to limit the output from high-volume log sites.
+package sample
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/bep/clocks"
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// numCounters is the number of sample counters.
+// Entries with keys hashing to the same counter are sampled together.
+const numCounters = 4096
+
+// Options holds options for the sample handler.
+type Options struct {
+	// First is the number of entries with the same level and message
+	// to pass through in each Tick.
+	First int
+
+	// Thereafter, if > 0, passes every Thereafter-th entry
+	// after the First in each Tick. If 0, they're all dropped.
+	Thereafter int
+
+	// Tick is the length of the sampling window.
+	// Default is 1 second.
+	Tick time.Duration
+
+	// DroppedField, if set, is the name of a field added to the next entry
+	// passed through for a given level and message with the number
+	// of entries dropped since the last one.
+	DroppedField string
+
+	// Clock is the clock used to determine the sampling window.
+	// If not set, the system clock is used.
+	Clock logg.Clock
+}
+
+// Handler implementation.
+type Handler struct {
+	opts    Options
+	handler logg.Handler
+	seed    maphash.Seed
+
+	mu       sync.Mutex
+	counters [numCounters]counter
+}
+
+type counter struct {
+	resetAt time.Time
+	n       int
+	dropped int64
+}
+
+// New handler sampling the entries passed to h.
+func New(h logg.Handler, opts Options) *Handler {
+	if opts.Tick <= 0 {
+		opts.Tick = time.Second
+	}
+	if opts.Clock == nil {
+		opts.Clock = clocks.System()
+	}
+	return &Handler{
+		opts:    opts,
+		handler: h,
+		seed:    maphash.MakeSeed(),
+	}
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	now := h.opts.Clock.Now()
+	i := h.counterIndex(e)
+
+	h.mu.Lock()
+	c := &h.counters[i]
+	if !now.Before(c.resetAt) {
+		c.resetAt = now.Add(h.opts.Tick)
+		c.n = 0
+	}
+	c.n++
+	if !h.sample(c.n) {
+		c.dropped++
+		h.mu.Unlock()
+		return nil
+	}
+	dropped := c.dropped
+	c.dropped = 0
+	h.mu.Unlock()
+
+	if dropped > 0 && h.opts.DroppedField != "" {
+		// Make sure we don't modify the entry seen by other handlers.
+		x := *e
+		x.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], logg.Int64(h.opts.DroppedField, dropped))
+		e = &x
+	}
+
+	return logg.HandleLogContext(ctx, h.handler, e)
+}
+
+func (h *Handler) sample(n int) bool {
+	if n <= h.opts.First {
+		return true
+	}
+	return h.opts.Thereafter > 0 && (n-h.opts.First)%h.opts.Thereafter == 0
+}
+
+func (h *Handler) counterIndex(e *logg.Entry) uint64 {
+	var mh maphash.Hash
+	mh.SetSeed(h.seed)
+	var level [8]byte
+	binary.LittleEndian.PutUint64(level[:], uint64(e.Level))
+	mh.Write(level[:])
+	mh.WriteString(e.Message)
+	return mh.Sum64() % numCounters
+}
+
+// Flush implements logg.Flusher by flushing the wrapped handler.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.Flush(ctx, h.handler)
+}
+
+// Close implements logg.Closer by closing the wrapped handler.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.Close(ctx, h.handler)
+}
@@ -0,0 +1,98 @@
+package sample_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/memory"
+	"github.com/bep/logg/handlers/multi"
+	"github.com/bep/logg/handlers/sample"
+	qt "github.com/frankban/quicktest"
+)
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.t
+}
+
+func TestSample(t *testing.T) {
+	clock := &testClock{t: time.Date(2022, 8, 12, 10, 0, 0, 0, time.UTC)}
+	h := memory.New()
+	l := logg.New(logg.Options{
+		Level:   logg.LevelInfo,
+		Handler: sample.New(h, sample.Options{First: 2, Thereafter: 3, Tick: time.Second, Clock: clock}),
+	})
+	info := l.WithLevel(logg.LevelInfo)
+
+	for i := range 10 {
+		info.WithField("i", i).Log(logg.String("hot"))
+	}
+
+	messages := func() []string {
+		var s []string
+		for _, e := range h.Entries {
+			s = append(s, fmt.Sprintf("%s:%v", e.Message, e.Fields[0].Value))
+		}
+		return s
+	}
+
+	// Different message, sampled separately.
+	info = info.WithField("i", "x")
+	info.Log(logg.String("cold"))
+
+	// 1, 2, then every 3rd: 5, 8.
+	qt.Assert(t, messages(), qt.DeepEquals, []string{"hot:0", "hot:1", "hot:4", "hot:7", "cold:x"})
+
+	// New window.
+	clock.t = clock.t.Add(time.Second)
+	info.Log(logg.String("hot"))
+	qt.Assert(t, h.Entries, qt.HasLen, 6)
+	qt.Assert(t, h.Entries[5].Message, qt.Equals, "hot")
+}
+
+func TestSampleDroppedField(t *testing.T) {
+	clock := &testClock{t: time.Date(2022, 8, 12, 10, 0, 0, 0, time.UTC)}
+	h := memory.New()
+	sibling := memory.New()
+	l := logg.New(logg.Options{
+		Level: logg.LevelInfo,
+		Handler: multi.New(
+			sample.New(h, sample.Options{First: 1, Tick: time.Second, DroppedField: "dropped", Clock: clock}),
+			sibling,
+		),
+	})
+	info := l.WithLevel(logg.LevelInfo)
+
+	for range 5 {
+		info.Log(logg.String("hot"))
+	}
+	clock.t = clock.t.Add(time.Second)
+	info.Log(logg.String("hot"))
+	info.WithLevel(logg.LevelWarn).Log(logg.String("hot"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 3)
+	qt.Assert(t, h.Entries[0].Fields, qt.HasLen, 0)
+	qt.Assert(t, h.Entries[1].Fields, qt.DeepEquals, logg.Fields{logg.Int64("dropped", 4)})
+	qt.Assert(t, h.Entries[2].Level, qt.Equals, logg.LevelWarn)
+	qt.Assert(t, h.Entries[2].Fields, qt.HasLen, 0)
+
+	qt.Assert(t, sibling.Entries, qt.HasLen, 7)
+	for _, e := range sibling.Entries {
+		qt.Assert(t, e.Fields, qt.HasLen, 0)
+	}
+}
+
+func BenchmarkSample(b *testing.B) {
+	h := sample.New(logg.HandlerFunc(func(e *logg.Entry) error { return nil }), sample.Options{First: 10, Thereafter: 100})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+	info := l.WithLevel(logg.LevelInfo)
+
+	for b.Loop() {
+		info.Log(logg.String("hot"))
+	}
+}
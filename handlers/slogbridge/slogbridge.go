@@ -0,0 +1,282 @@
+// Package slogbridge connects logg with the standard library's log/slog package.
+package slogbridge
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ slog.Handler        = (*SlogHandler)(nil)
+	_ logg.ContextHandler = (*Handler)(nil)
+)
+
+// LevelTrace is the slog.Level logg.LevelTrace is mapped to by default.
+// slog has no trace level, so this will be rendered as "DEBUG-4" by the
+// slog handlers in the standard library.
+const LevelTrace = slog.LevelDebug - 4
+
+// DefaultLevels is the default logg.Level to slog.Level mapping used by Handler.
+var DefaultLevels = map[logg.Level]slog.Level{
+	logg.LevelTrace:    LevelTrace,
+	logg.LevelDebug:    slog.LevelDebug,
+	logg.LevelInfo:     slog.LevelInfo,
+	logg.LevelNotice:   slog.LevelInfo + 2,
+	logg.LevelWarn:     slog.LevelWarn,
+	logg.LevelError:    slog.LevelError,
+	logg.LevelCritical: slog.LevelError + 2,
+	logg.LevelPanic:    slog.LevelError + 4,
+	logg.LevelFatal:    slog.LevelError + 8,
+}
+
+// Options holds options for Handler.
+type Options struct {
+	// Levels maps logg levels to slog levels.
+	// Levels not in this map are looked up in DefaultLevels, and levels
+	// in neither get the slog level of the closest lower logg level.
+	Levels map[logg.Level]slog.Level
+}
+
+// LoggerKey is the key of the attribute holding Entry.LoggerName,
+// added by Handler for entries logged with a named logger.
+const LoggerKey = "logger"
+
+// Handler implements logg.Handler by forwarding entries to a slog.Handler.
+//
+// The name of a named logger is added as an attribute with LoggerKey,
+// and Entry.Source as a *slog.Source attribute with slog.SourceKey.
+type Handler struct {
+	h      slog.Handler
+	levels map[logg.Level]slog.Level
+}
+
+// New creates a new logg.Handler that forwards entries to h.
+func New(h slog.Handler, opts Options) *Handler {
+	levels := make(map[logg.Level]slog.Level, len(DefaultLevels)+len(opts.Levels))
+	maps.Copy(levels, DefaultLevels)
+	maps.Copy(levels, opts.Levels)
+	return &Handler{
+		h:      h,
+		levels: levels,
+	}
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	level := h.slogLevel(e.Level)
+	if !h.h.Enabled(ctx, level) {
+		return nil
+	}
+
+	r := slog.NewRecord(e.Timestamp, level, e.Message, 0)
+	if e.LoggerName != "" {
+		r.AddAttrs(slog.String(LoggerKey, e.LoggerName))
+	}
+	if e.Source != (logg.Frame{}) {
+		r.AddAttrs(slog.Any(slog.SourceKey, &slog.Source{
+			Function: e.Source.Function,
+			File:     e.Source.File,
+			Line:     e.Source.Line,
+		}))
+	}
+	for _, f := range e.Fields {
+		r.AddAttrs(attr(f))
+	}
+
+	return h.h.Handle(ctx, r)
+}
+
+// slogLevel maps level to a slog.Level, see Options.Levels.
+func (h *Handler) slogLevel(level logg.Level) slog.Level {
+	if l, found := h.levels[level]; found {
+		return l
+	}
+	closest, l := logg.LevelInvalid, LevelTrace
+	for k, v := range h.levels {
+		if k < level && k > closest {
+			closest, l = k, v
+		}
+	}
+	return l
+}
+
+// SlogHandler implements slog.Handler on top of a logg.Logger,
+// so records logged via slog are passed through the logger's Handler chain.
+type SlogHandler struct {
+	// One entry per logg level, see levelIndex.
+	entries [5]*logg.Entry
+
+	fields logg.Fields
+	prefix string
+}
+
+// NewSlogHandler creates a new slog.Handler that logs to l.
+//
+// Attributes are added as fields, and the keys of attributes inside groups
+// (from WithGroup or slog.Group) are prefixed with the dot separated group names.
+func NewSlogHandler(l logg.Logger) *SlogHandler {
+	return &SlogHandler{
+		entries: [...]*logg.Entry{
+			l.WithLevel(logg.LevelTrace),
+			l.WithLevel(logg.LevelDebug),
+			l.WithLevel(logg.LevelInfo),
+			l.WithLevel(logg.LevelWarn),
+			l.WithLevel(logg.LevelError),
+		},
+	}
+}
+
+// Level maps a slog.Level to a logg.Level.
+// Levels below slog.LevelDebug are mapped to logg.LevelTrace, and levels
+// above slog.LevelError to logg.LevelError, so a slog record never
+// panics or exits.
+func Level(level slog.Level) logg.Level {
+	return levels[levelIndex(level)]
+}
+
+var levels = [...]logg.Level{
+	logg.LevelTrace,
+	logg.LevelDebug,
+	logg.LevelInfo,
+	logg.LevelWarn,
+	logg.LevelError,
+}
+
+func levelIndex(level slog.Level) int {
+	switch {
+	case level < slog.LevelDebug:
+		return 0
+	case level < slog.LevelInfo:
+		return 1
+	case level < slog.LevelWarn:
+		return 2
+	case level < slog.LevelError:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.entries[levelIndex(level)].IsLevelEnabled()
+}
+
+// Handle implements slog.Handler.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := h.entries[levelIndex(r.Level)]
+	if !e.IsLevelEnabled() {
+		return nil
+	}
+
+	fields := make(logg.Fields, len(h.fields), len(h.fields)+r.NumAttrs())
+	copy(fields, h.fields)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	e.WithContext(ctx).WithFields(fields).Log(logg.String(r.Message))
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.fields = make(logg.Fields, len(h.fields), len(h.fields)+len(attrs))
+	copy(h2.fields, h.fields)
+	for _, a := range attrs {
+		h2.fields = appendAttr(h2.fields, h.prefix, a)
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+func attr(f logg.Field) slog.Attr {
+	switch f.Kind() {
+	case logg.KindString:
+		return slog.String(f.Name, f.Str())
+	case logg.KindInt64:
+		return slog.Int64(f.Name, f.Int64())
+	case logg.KindFloat64:
+		return slog.Float64(f.Name, f.Float64())
+	case logg.KindBool:
+		return slog.Bool(f.Name, f.Bool())
+	case logg.KindDuration:
+		return slog.Duration(f.Name, f.Duration())
+	case logg.KindTime:
+		return slog.Time(f.Name, f.Time())
+	case logg.KindGroup:
+		group := f.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, g := range group {
+			attrs[i] = attr(g)
+		}
+		return slog.Attr{Key: f.Name, Value: slog.GroupValue(attrs...)}
+	default:
+		if s, ok := f.Value.(logg.SecretValue); ok {
+			return slog.String(f.Name, s.String())
+		}
+		return slog.Any(f.Name, f.Value)
+	}
+}
+
+func appendAttr(fields logg.Fields, prefix string, a slog.Attr) logg.Fields {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, field(prefix+a.Key, a.Value))
+}
+
+func field(name string, v slog.Value) logg.Field {
+	switch v.Kind() {
+	case slog.KindString:
+		return logg.Str(name, v.String())
+	case slog.KindInt64:
+		return logg.Int64(name, v.Int64())
+	case slog.KindFloat64:
+		return logg.Float64(name, v.Float64())
+	case slog.KindBool:
+		return logg.Bool(name, v.Bool())
+	case slog.KindDuration:
+		return logg.Duration(name, v.Duration())
+	case slog.KindTime:
+		return logg.Time(name, v.Time())
+	default:
+		return logg.Any(name, v.Any())
+	}
+}
@@ -0,0 +1,177 @@
+package slogbridge_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/bep/clocks"
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/memory"
+	"github.com/bep/logg/handlers/slogbridge"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSlogHandler(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	sl := slog.New(slogbridge.NewSlogHandler(l))
+
+	sl.Debug("hidden", "a", 1)
+	sl.Info("hello", "user", "tj", slog.Int("id", 123))
+	sl.With("app", "hugo").WithGroup("http").Warn("slow", "method", "GET", slog.Group("res", "status", 200))
+	sl.Error("boom", slog.Group("empty"), slog.Group("", "inlined", true))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 3)
+
+	qt.Assert(t, h.Entries[0].Level, qt.Equals, logg.LevelInfo)
+	qt.Assert(t, h.Entries[0].Message, qt.Equals, "hello")
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{{Name: "user", Value: "tj"}, {Name: "id", Value: int64(123)}})
+
+	qt.Assert(t, h.Entries[1].Level, qt.Equals, logg.LevelWarn)
+	qt.Assert(t, h.Entries[1].Fields, qt.DeepEquals, logg.Fields{{Name: "app", Value: "hugo"}, {Name: "http.method", Value: "GET"}, {Name: "http.res.status", Value: int64(200)}})
+
+	qt.Assert(t, h.Entries[2].Level, qt.Equals, logg.LevelError)
+	qt.Assert(t, h.Entries[2].Fields, qt.DeepEquals, logg.Fields{{Name: "inlined", Value: true}})
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	l := logg.New(logg.Options{Level: logg.LevelWarn, Handler: memory.New()})
+	h := slogbridge.NewSlogHandler(l)
+
+	qt.Assert(t, h.Enabled(t.Context(), slog.LevelInfo), qt.IsFalse)
+	qt.Assert(t, h.Enabled(t.Context(), slog.LevelWarn), qt.IsTrue)
+	qt.Assert(t, h.Enabled(t.Context(), slog.LevelError+4), qt.IsTrue)
+}
+
+func TestLevel(t *testing.T) {
+	qt.Assert(t, slogbridge.Level(slog.LevelDebug-4), qt.Equals, logg.LevelTrace)
+	qt.Assert(t, slogbridge.Level(slog.LevelDebug), qt.Equals, logg.LevelDebug)
+	qt.Assert(t, slogbridge.Level(slog.LevelInfo+1), qt.Equals, logg.LevelInfo)
+	qt.Assert(t, slogbridge.Level(slog.LevelWarn), qt.Equals, logg.LevelWarn)
+	qt.Assert(t, slogbridge.Level(slog.LevelError), qt.Equals, logg.LevelError)
+}
+
+func BenchmarkSlogHandlerDisabled(b *testing.B) {
+	l := logg.New(logg.Options{Level: logg.LevelError, Handler: memory.New()})
+	sl := slog.New(slogbridge.NewSlogHandler(l))
+
+	for b.Loop() {
+		sl.Info("hello", "user", "tj")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slogbridge.LevelTrace})
+	l := logg.New(
+		logg.Options{
+			Level:   logg.LevelTrace,
+			Handler: slogbridge.New(sh, slogbridge.Options{Levels: map[logg.Level]slog.Level{logg.LevelWarn: slog.LevelWarn + 1}}),
+			Clock:   clocks.Fixed(clocks.TimeCupFinalNorway1976),
+		})
+
+	l.WithLevel(logg.LevelTrace).Log(logg.String("tracing"))
+	l.WithLevel(logg.LevelInfo).WithField("user", "tj").WithField("id", 123).Log(logg.String("hello"))
+	l.WithLevel(logg.LevelWarn).Log(logg.String("careful"))
+
+	qt.Assert(t, buf.String(), qt.Equals, `time=1976-10-24T12:15:02.127Z level=DEBUG-4 msg=tracing
+time=1976-10-24T12:15:02.127Z level=INFO msg=hello user=tj id=123
+time=1976-10-24T12:15:02.127Z level=WARN+1 msg=careful
+`)
+}
+
+func TestHandlerDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: slogbridge.New(sh, slogbridge.Options{})})
+
+	l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+	l.WithLevel(logg.LevelError).Log(logg.String("boom"))
+
+	qt.Assert(t, strings.Count(buf.String(), "\n"), qt.Equals, 1)
+	qt.Assert(t, buf.String(), qt.Contains, "level=ERROR msg=boom")
+}
+
+func TestHandlerCustomLevels(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slogbridge.LevelTrace})
+	l := logg.New(logg.Options{Level: logg.Level(1), Handler: slogbridge.New(sh, slogbridge.Options{})})
+
+	l.WithLevel(logg.LevelNotice).Log(logg.String("notice"))
+	l.WithLevel(logg.LevelCritical).Log(logg.String("critical"))
+	// Not registered, mapped as the closest lower level.
+	l.WithLevel(logg.Level(45)).Log(logg.String("custom"))
+	l.WithLevel(logg.Level(5)).Log(logg.String("low"))
+
+	qt.Assert(t, buf.String(), qt.Contains, "level=INFO+2 msg=notice")
+	qt.Assert(t, buf.String(), qt.Contains, "level=ERROR+2 msg=critical")
+	qt.Assert(t, buf.String(), qt.Contains, "level=WARN msg=custom")
+	qt.Assert(t, buf.String(), qt.Contains, "level=DEBUG-4 msg=low")
+}
+
+func TestHandlerGroup(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewJSONHandler(&buf, nil)
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: slogbridge.New(sh, slogbridge.Options{})})
+
+	l.WithLevel(logg.LevelInfo).WithGroup("http").WithField("method", "GET").WithField("status", 200).Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Contains, `"http":{"method":"GET","status":200}`)
+}
+
+func TestHandlerLoggerNameAndSource(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewJSONHandler(&buf, nil)
+	l := logg.New(logg.Options{Level: logg.LevelInfo, AddSource: true, Handler: slogbridge.New(sh, slogbridge.Options{})})
+
+	l.Named("cache").WithLevel(logg.LevelInfo).WithField("key", "a").Log(logg.String("hit"))
+
+	qt.Assert(t, buf.String(), qt.Contains, `"msg":"hit","logger":"cache","source":{"function":"github.com/bep/logg/handlers/slogbridge_test.TestHandlerLoggerNameAndSource","file":`)
+	qt.Assert(t, buf.String(), qt.Contains, `slogbridge_test.go","line":`)
+	qt.Assert(t, buf.String(), qt.Contains, `"key":"a"`)
+
+	buf.Reset()
+	l = logg.New(logg.Options{Level: logg.LevelInfo, Handler: slogbridge.New(sh, slogbridge.Options{})})
+	l.WithLevel(logg.LevelInfo).Log(logg.String("hit"))
+	qt.Assert(t, buf.String(), qt.Not(qt.Contains), `"logger"`)
+	qt.Assert(t, buf.String(), qt.Not(qt.Contains), `"source"`)
+}
+
+func TestHandlerSecret(t *testing.T) {
+	var buf bytes.Buffer
+	sh := slog.NewJSONHandler(&buf, nil)
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: slogbridge.New(sh, slogbridge.Options{})})
+
+	l.WithLevel(logg.LevelInfo).WithField("token", logg.Secret("abc")).Log(logg.String("login"))
+
+	qt.Assert(t, buf.String(), qt.Contains, `"token":"***"`)
+}
+
+type ctxKey string
+
+func TestContext(t *testing.T) {
+	var values []any
+	sh := contextSlogHandler(func(ctx context.Context, r slog.Record) error {
+		values = append(values, ctx.Value(ctxKey("trace")))
+		return nil
+	})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: slogbridge.New(sh, slogbridge.Options{})})
+	sl := slog.New(slogbridge.NewSlogHandler(l))
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t1")
+	sl.InfoContext(ctx, "hello")
+	sl.Info("world")
+
+	qt.Assert(t, values, qt.DeepEquals, []any{"t1", nil})
+}
+
+type contextSlogHandler func(ctx context.Context, r slog.Record) error
+
+func (h contextSlogHandler) Enabled(context.Context, slog.Level) bool        { return true }
+func (h contextSlogHandler) Handle(ctx context.Context, r slog.Record) error { return h(ctx, r) }
+func (h contextSlogHandler) WithAttrs([]slog.Attr) slog.Handler              { return h }
+func (h contextSlogHandler) WithGroup(string) slog.Handler                   { return h }
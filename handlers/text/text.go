@@ -39,12 +39,52 @@ func New(w io.Writer, opts Options) *Handler {
 
 // HandleLog implements logg.Handler.
 func (h *Handler) HandleLog(e *logg.Entry) error {
-	fields := make([]string, len(e.Fields))
-	for i, f := range e.Fields {
-		fields[i] = fmt.Sprintf("%s=%v", f.Name, f.Value)
+	sep := h.opts.Separator
+
+	b := make([]byte, 0, 256)
+	b = append(b, strings.ToUpper(e.Level.String())...)
+	if e.LoggerName != "" {
+		b = append(b, sep...)
+		b = append(b, e.LoggerName...)
+	}
+	b = append(b, sep...)
+	b = append(b, e.Message...)
+	b = append(b, sep...)
+
+	b = appendFields(b, len(b), sep, "", e.Fields)
+	if e.Source.Line != 0 {
+		if len(e.Fields) > 0 {
+			b = append(b, sep...)
+		}
+		b = append(b, "source="...)
+		b = append(b, e.Source.String()...)
 	}
+	b = append(b, '\n')
 
-	fmt.Fprintf(h.w, "%s%s%s%s%s\n", strings.ToUpper(e.Level.String()), h.opts.Separator, e.Message, h.opts.Separator, strings.Join(fields, h.opts.Separator))
+	for _, f := range e.Stack {
+		b = fmt.Appendf(b, "\t%s\n\t\t%s\n", f.Function, f)
+	}
 
-	return nil
+	_, err := h.w.Write(b)
+	return err
+}
+
+// appendFields appends fields separated by sep, with the fields in groups
+// prefixed with the dot separated group names.
+// start is the length of b before the first field.
+func appendFields(b []byte, start int, sep, prefix string, fields logg.Fields) []byte {
+	for _, f := range fields {
+		if f.Kind() == logg.KindGroup {
+			b = appendFields(b, start, sep, prefix+f.Name+".", f.Group())
+			continue
+		}
+		if len(b) > start {
+			b = append(b, sep...)
+		}
+		b = append(b, prefix...)
+		b = append(b, f.Name...)
+		b = append(b, '=')
+		b = f.AppendValue(b)
+	}
+	return b
 }
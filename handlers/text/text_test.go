@@ -3,6 +3,7 @@ package text_test
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 
@@ -23,3 +24,59 @@ func TestTextHandler(t *testing.T) {
 
 	qt.Assert(t, buf.String(), qt.Equals, expected)
 }
+
+func TestTextHandlerNamed(t *testing.T) {
+	var buf bytes.Buffer
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: text.New(&buf, text.Options{Separator: "|"})})
+	info := l.Named("cache").Named("fs").WithLevel(logg.LevelInfo)
+
+	info.WithField("user", "tj").Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Equals, "INFO|cache.fs|hello|user=tj\n")
+}
+
+func TestTextHandlerSource(t *testing.T) {
+	var buf bytes.Buffer
+	l := logg.New(logg.Options{Level: logg.LevelInfo, AddSource: true, Handler: text.New(&buf, text.Options{Separator: "|"})})
+
+	l.WithLevel(logg.LevelInfo).WithField("user", "tj").Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Matches, `INFO\|hello\|user=tj\|source=.*text_test\.go:\d+\n`)
+}
+
+func TestTextHandlerStack(t *testing.T) {
+	var buf bytes.Buffer
+	l := logg.New(logg.Options{Level: logg.LevelInfo, StackLevel: logg.LevelError, Handler: text.New(&buf, text.Options{Separator: "|"})})
+
+	l.WithLevel(logg.LevelError).Log(logg.String("boom"))
+
+	qt.Assert(t, buf.String(), qt.Matches, `(?s)ERROR\|boom\|\n\tgithub.com/bep/logg/handlers/text_test.TestTextHandlerStack\n\t\t.*text_test\.go:\d+\n.*`)
+}
+
+func TestTextHandlerTypedFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: text.New(&buf, text.Options{})})
+
+	l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{
+		logg.Str("user", "tj"),
+		logg.Int64("id", 123),
+		logg.Bool("admin", true),
+		logg.Duration("took", 1500*time.Millisecond),
+	}).Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Equals, "INFO hello user=tj id=123 admin=true took=1.5s\n")
+}
+
+func TestTextHandlerGroups(t *testing.T) {
+	var buf bytes.Buffer
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: text.New(&buf, text.Options{Separator: " "})})
+
+	l.WithLevel(logg.LevelInfo).WithField("user", "tj").WithGroup("http").WithField("method", "GET").WithGroup("res").WithField("status", 200).Log(logg.String("hello"))
+
+	qt.Assert(t, buf.String(), qt.Equals, "INFO hello user=tj http.method=GET http.res.status=200\n")
+
+	// Empty groups print nothing.
+	buf.Reset()
+	l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{logg.Group("g"), logg.Str("a", "b"), logg.Group("h", logg.Group("i")), logg.Str("c", "d")}).Log(logg.String("hello"))
+	qt.Assert(t, buf.String(), qt.Equals, "INFO hello a=b c=d\n")
+}
@@ -0,0 +1,158 @@
+// Package transform implements a handler which transforms the fields, level,
+// message and timestamp of log entries, e.g. to match the schema of a log platform.
+package transform
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bep/logg"
+)
+
+// assert interface compliance.
+var (
+	_ logg.ContextHandler = (*Handler)(nil)
+	_ logg.Flusher        = (*Handler)(nil)
+	_ logg.Closer         = (*Handler)(nil)
+)
+
+// Options holds options for the transform handler.
+// The field options are applied in the order Drop, Rename and Field.
+//
+// The options also apply to the fields in groups. Drop and Rename match
+// a field in a group by either its dot separated path, e.g. "http.password"
+// for the field password in the group http, or by its own name, e.g.
+// "password". The paths are made of the original names, before any renames.
+type Options struct {
+	// Drop holds the names of the fields to remove.
+	Drop []string
+
+	// Rename maps field names to new names, e.g. "duration" to "duration_ms".
+	// The new name replaces the field's own name, not its path.
+	Rename map[string]string
+
+	// Field, if set, is called for each field, including the fields in
+	// groups, which are transformed before their group is passed to Field.
+	// It returns the field to pass on, and false if the field should be removed.
+	Field func(f logg.Field) (logg.Field, bool)
+
+	// Level, if set, returns the level to pass on.
+	Level func(level logg.Level) logg.Level
+
+	// Message, if set, returns the message to pass on.
+	Message func(msg string) string
+
+	// Timestamp, if set, returns the timestamp to pass on.
+	Timestamp func(t time.Time) time.Time
+}
+
+// Handler implementation.
+//
+// The entry is not modified, the wrapped handler gets a transformed copy.
+type Handler struct {
+	opts    Options
+	handler logg.Handler
+
+	drop map[string]bool
+}
+
+// New handler transforming the entries passed to h.
+func New(h logg.Handler, opts Options) *Handler {
+	drop := make(map[string]bool, len(opts.Drop))
+	for _, name := range opts.Drop {
+		drop[name] = true
+	}
+	return &Handler{
+		opts:    opts,
+		handler: h,
+		drop:    drop,
+	}
+}
+
+// The copies are only valid during the call to the wrapped handler,
+// so they can be reused.
+var entryPool = &sync.Pool{
+	New: func() any {
+		return &logg.Entry{}
+	},
+}
+
+// HandleLog implements logg.Handler.
+func (h *Handler) HandleLog(e *logg.Entry) error {
+	return h.HandleLogContext(e.Context(), e)
+}
+
+// HandleLogContext implements logg.ContextHandler.
+func (h *Handler) HandleLogContext(ctx context.Context, e *logg.Entry) error {
+	x := entryPool.Get().(*logg.Entry)
+	fields := x.Fields[:0]
+	*x = *e
+	defer func() {
+		clear(x.Fields)
+		*x = logg.Entry{Fields: x.Fields[:0]}
+		entryPool.Put(x)
+	}()
+
+	x.Fields = h.appendFields(fields, "", e.Fields)
+
+	if h.opts.Level != nil {
+		x.Level = h.opts.Level(x.Level)
+	}
+	if h.opts.Message != nil {
+		x.Message = h.opts.Message(x.Message)
+	}
+	if h.opts.Timestamp != nil {
+		x.Timestamp = h.opts.Timestamp(x.Timestamp)
+	}
+
+	return logg.HandleLogContext(ctx, h.handler, x)
+}
+
+// appendFields appends the transformed fields to dst.
+// The prefix is the path of the group holding fields, e.g. "http.".
+func (h *Handler) appendFields(dst logg.Fields, prefix string, fields logg.Fields) logg.Fields {
+	for _, f := range fields {
+		path := f.Name
+		if prefix != "" {
+			path = prefix + f.Name
+		}
+		if h.drop[path] || prefix != "" && h.drop[f.Name] {
+			continue
+		}
+		if f.Kind() == logg.KindGroup {
+			f = logg.Group(f.Name, h.appendFields(nil, path+".", f.Group())...)
+		}
+		if name, found := h.rename(path, f.Name); found {
+			f.Name = name
+		}
+		if h.opts.Field != nil {
+			var keep bool
+			if f, keep = h.opts.Field(f); !keep {
+				continue
+			}
+		}
+		dst = append(dst, f)
+	}
+	return dst
+}
+
+// rename returns the new name for the field with the given path and name,
+// preferring a match on the path.
+func (h *Handler) rename(path, name string) (string, bool) {
+	if newName, found := h.opts.Rename[path]; found || path == name {
+		return newName, found
+	}
+	newName, found := h.opts.Rename[name]
+	return newName, found
+}
+
+// Flush implements logg.Flusher by flushing the wrapped handler.
+func (h *Handler) Flush(ctx context.Context) error {
+	return logg.Flush(ctx, h.handler)
+}
+
+// Close implements logg.Closer by closing the wrapped handler.
+func (h *Handler) Close(ctx context.Context) error {
+	return logg.Close(ctx, h.handler)
+}
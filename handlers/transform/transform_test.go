@@ -0,0 +1,118 @@
+package transform_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bep/clocks"
+	"github.com/bep/logg"
+	"github.com/bep/logg/handlers/memory"
+	"github.com/bep/logg/handlers/multi"
+	"github.com/bep/logg/handlers/transform"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestTransform(t *testing.T) {
+	h := memory.New()
+	sibling := memory.New()
+	tr := transform.New(h, transform.Options{
+		Drop:   []string{"noisy"},
+		Rename: map[string]string{"duration": "duration_ms", "error": "err.message"},
+		Field: func(f logg.Field) (logg.Field, bool) {
+			if f.Name == "user" {
+				return logg.Str(f.Name, strings.ToUpper(f.Str())), true
+			}
+			if f.Name == "debug" {
+				return f, false
+			}
+			return f, true
+		},
+		Level: func(level logg.Level) logg.Level {
+			if level == logg.LevelTrace {
+				return logg.LevelDebug
+			}
+			return level
+		},
+		Message:   strings.ToUpper,
+		Timestamp: func(t time.Time) time.Time { return t.UTC().Truncate(time.Second) },
+	})
+	l := logg.New(logg.Options{
+		Level:   logg.LevelTrace,
+		Handler: multi.New(tr, sibling),
+		Clock:   clocks.Fixed(clocks.TimeCupFinalNorway1976),
+	})
+
+	l.WithLevel(logg.LevelTrace).
+		WithFields(logg.Fields{logg.Str("user", "tj"), logg.Int("noisy", 1), logg.Bool("debug", true)}).
+		WithDuration(1500 * time.Millisecond).
+		WithError(errBoom{}).
+		Log(logg.String("hello"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+	e := h.Entries[0]
+	qt.Assert(t, e.Level, qt.Equals, logg.LevelDebug)
+	qt.Assert(t, e.Message, qt.Equals, "HELLO")
+	qt.Assert(t, e.Timestamp, qt.Equals, clocks.TimeCupFinalNorway1976.UTC().Truncate(time.Second))
+	qt.Assert(t, e.Fields, qt.DeepEquals, logg.Fields{
+		logg.Str("user", "TJ"),
+		{Name: "duration_ms", Value: int64(1500)},
+		{Name: "err.message", Value: "boom"},
+	})
+
+	// Siblings in a multi.Handler see the original entry.
+	qt.Assert(t, sibling.Entries[0].Level, qt.Equals, logg.LevelTrace)
+	qt.Assert(t, sibling.Entries[0].Message, qt.Equals, "hello")
+	qt.Assert(t, sibling.Entries[0].Fields, qt.HasLen, 5)
+	qt.Assert(t, sibling.Entries[0].Fields[0], qt.DeepEquals, logg.Str("user", "tj"))
+}
+
+func TestTransformGroups(t *testing.T) {
+	h := memory.New()
+	tr := transform.New(h, transform.Options{
+		Drop:   []string{"password", "http.internal"},
+		Rename: map[string]string{"http.status": "status_code", "method": "verb"},
+		Field: func(f logg.Field) (logg.Field, bool) {
+			if f.Name == "user" {
+				return logg.Str(f.Name, strings.ToUpper(f.Str())), true
+			}
+			return f, true
+		},
+	})
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: tr})
+
+	l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{
+		logg.Str("password", "secret"),
+		logg.Group("http",
+			logg.Str("method", "GET"),
+			logg.Int("status", 200),
+			logg.Bool("internal", true),
+			logg.Group("auth", logg.Str("user", "tj"), logg.Str("password", "secret")),
+		),
+		logg.Int("status", 201),
+	}).Log(logg.String("hello"))
+
+	qt.Assert(t, h.Entries[0].Fields, qt.DeepEquals, logg.Fields{
+		logg.Group("http",
+			logg.Str("verb", "GET"),
+			logg.Int("status_code", 200),
+			logg.Group("auth", logg.Str("user", "TJ")),
+		),
+		logg.Int("status", 201),
+	})
+}
+
+func BenchmarkTransform(b *testing.B) {
+	tr := transform.New(logg.HandlerFunc(func(e *logg.Entry) error { return nil }), transform.Options{
+		Rename: map[string]string{"duration": "duration_ms"},
+	})
+	e := &logg.Entry{Fields: logg.Fields{logg.Str("user", "tj"), logg.Int64("duration", 12)}}
+
+	for b.Loop() {
+		tr.HandleLog(e)
+	}
+}
+
+type errBoom struct{}
+
+func (errBoom) Error() string { return "boom" }
@@ -1,6 +1,7 @@
 package logg
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -9,6 +10,35 @@ import (
 type Logger interface {
 	// WithLevel returns a new entry with `level` set.
 	WithLevel(Level) *Entry
+
+	// Level returns the minimum level to log at.
+	Level() Level
+
+	// SetLevel sets the minimum level to log at.
+	// This is safe to call while logging from other goroutines.
+	// For a named logger, this does not change the level of its parent
+	// or siblings, see Options.NameLevels.
+	SetLevel(Level)
+
+	// Named returns a child logger with name appended to this logger's
+	// name, separated by a dot, e.g. "cache.fs".
+	// The child's level is resolved from Options.NameLevels when it's created.
+	Named(name string) Logger
+
+	// RegisterExitHook adds hook to the hooks run after an entry is logged
+	// at LevelFatal, before exiting, e.g. to close a database.
+	// The hooks are shared with all loggers created with Named.
+	// This is safe to call while logging from other goroutines.
+	RegisterExitHook(hook func())
+
+	// Flush flushes the Handler if it implements Flusher,
+	// e.g. to make sure all entries are written before exiting.
+	Flush(ctx context.Context) error
+
+	// Close closes the Handler if it implements Closer, see Close.
+	// The Handler is shared with all loggers created with Named,
+	// none of which can be used after Close is called.
+	Close(ctx context.Context) error
 }
 
 // LevelLogger is the logger at a given level.
@@ -24,10 +54,18 @@ type LevelLogger interface {
 	// WithLevel returns a new entry with `level` set.
 	WithLevel(Level) *Entry
 
+	// WithContext returns a new entry with `ctx` set.
+	WithContext(ctx context.Context) *Entry
+
 	// WithFields returns a new entry with the`fields` in fields set.
 	// This is a noop if LevelLogger's level is less than Logger's.
 	WithFields(fields Fielder) *Entry
 
+	// WithGroup returns a new entry where the fields added after this call
+	// are put in a group with the given name, see Group.
+	// This is a noop if LevelLogger's level is less than Logger's.
+	WithGroup(name string) *Entry
+
 	// WithLevel returns a new entry with the field f set with value v
 	// This is a noop if LevelLogger's level is less than Logger's.
 	WithField(f string, v any) *Entry
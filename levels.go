@@ -2,46 +2,199 @@ package logg
 
 import (
 	"bytes"
+	"cmp"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // ErrInvalidLevel is returned if the severity level is invalid.
 var ErrInvalidLevel = errors.New("invalid level")
 
 // Level of severity.
+// The levels are spaced apart to leave room for custom levels,
+// see RegisterLevel. Note that this changed the values of the levels
+// from 1 (trace) to 5 (error) in earlier versions, see the README.
 type Level int
 
 // Log levels.
 const (
-	LevelInvalid Level = iota
-	LevelTrace
-	LevelDebug
-	LevelInfo
-	LevelWarn
-	LevelError
+	LevelInvalid  Level = 0
+	LevelTrace    Level = 10
+	LevelDebug    Level = 20
+	LevelInfo     Level = 30
+	LevelNotice   Level = 35
+	LevelWarn     Level = 40
+	LevelError    Level = 50
+	LevelCritical Level = 55
+
+	// LevelPanic logs the entry and then panics with the message.
+	LevelPanic Level = 60
+
+	// LevelFatal logs the entry, closes the handler chain, runs the exit
+	// hooks and then exits, see Options.ExitFunc.
+	LevelFatal Level = 70
 )
 
-var levelNames = [...]string{
-	LevelTrace: "trace",
-	LevelDebug: "debug",
-	LevelInfo:  "info",
-	LevelWarn:  "warn",
-	LevelError: "error",
+// LevelSpec describes a level, see RegisterLevel.
+type LevelSpec struct {
+	// Level is the numeric severity, higher is more severe.
+	Level Level
+
+	// Name is the name returned by Level.String and accepted by ParseLevel.
+	// It must be lower case and can only contain a-z, 0-9, '-' and '_'.
+	Name string
+
+	// Aliases are other names accepted by ParseLevel, e.g. "warning".
+	Aliases []string
+
+	// Color is the default color used by the cli handler as ANSI SGR
+	// parameters, e.g. []int{31} for red.
+	Color []int
+
+	// Symbol is the default symbol used by the cli handler, e.g. "•".
+	Symbol string
 }
 
-var levelStrings = map[string]Level{
-	"trace":   LevelTrace,
-	"debug":   LevelDebug,
-	"info":    LevelInfo,
-	"warn":    LevelWarn,
-	"warning": LevelWarn,
-	"error":   LevelError,
+var builtinLevels = []LevelSpec{
+	{Level: LevelTrace, Name: "trace", Color: []int{37}, Symbol: "•"},
+	{Level: LevelDebug, Name: "debug", Color: []int{37}, Symbol: "•"},
+	{Level: LevelInfo, Name: "info", Color: []int{34}, Symbol: "•"},
+	{Level: LevelNotice, Name: "notice", Color: []int{36}, Symbol: "•"},
+	{Level: LevelWarn, Name: "warn", Aliases: []string{"warning"}, Color: []int{33}, Symbol: "•"},
+	{Level: LevelError, Name: "error", Color: []int{31}, Symbol: "⨯"},
+	{Level: LevelCritical, Name: "critical", Aliases: []string{"crit"}, Color: []int{35}, Symbol: "⨯"},
+	{Level: LevelPanic, Name: "panic", Color: []int{31, 1}, Symbol: "⨯"},
+	{Level: LevelFatal, Name: "fatal", Color: []int{31, 1}, Symbol: "⨯"},
 }
 
-// String implementation.
+// levelRegistry holds the registered levels.
+// It's never modified after it's stored in levels.
+type levelRegistry struct {
+	specs map[Level]LevelSpec
+	names map[string]Level
+}
+
+var (
+	levelsMu sync.Mutex
+	levels   atomic.Pointer[levelRegistry]
+)
+
+func init() {
+	r := &levelRegistry{
+		specs: make(map[Level]LevelSpec),
+		names: make(map[string]Level),
+	}
+	for _, spec := range builtinLevels {
+		if err := r.add(spec); err != nil {
+			panic(err)
+		}
+	}
+	levels.Store(r)
+}
+
+// RegisterLevel registers a custom level, e.g.
+//
+//	const LevelAudit logg.Level = 45
+//
+//	func init() {
+//		logg.MustRegisterLevel(logg.LevelSpec{Level: LevelAudit, Name: "audit", Color: []int{35}, Symbol: "!"})
+//	}
+//
+// The built-in levels can't be changed, and neither the level nor its
+// names can already be registered.
+// Levels should be registered before they're used, typically in an init func.
+func RegisterLevel(spec LevelSpec) error {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	old := levels.Load()
+	r := &levelRegistry{
+		specs: maps.Clone(old.specs),
+		names: maps.Clone(old.names),
+	}
+	if err := r.add(spec); err != nil {
+		return err
+	}
+	levels.Store(r)
+	return nil
+}
+
+// MustRegisterLevel registers a custom level or panics, see RegisterLevel.
+func MustRegisterLevel(spec LevelSpec) {
+	if err := RegisterLevel(spec); err != nil {
+		panic(err)
+	}
+}
+
+func (r *levelRegistry) add(spec LevelSpec) error {
+	if spec.Level <= LevelInvalid {
+		return fmt.Errorf("%w: %d must be greater than 0", ErrInvalidLevel, spec.Level)
+	}
+	if existing, found := r.specs[spec.Level]; found {
+		return fmt.Errorf("level %d is already registered as %q", spec.Level, existing.Name)
+	}
+	names := append([]string{spec.Name}, spec.Aliases...)
+	for i, name := range names {
+		if !validLevelName(name) {
+			return fmt.Errorf("%w: invalid name %q", ErrInvalidLevel, name)
+		}
+		if _, found := r.names[name]; found || slices.Contains(names[:i], name) {
+			return fmt.Errorf("level name %q is already registered", name)
+		}
+	}
+
+	spec.Aliases = slices.Clone(spec.Aliases)
+	spec.Color = slices.Clone(spec.Color)
+	r.specs[spec.Level] = spec
+	for _, name := range names {
+		r.names[name] = spec.Level
+	}
+	return nil
+}
+
+func validLevelName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// LookupLevel returns the spec for level and whether it's registered.
+func LookupLevel(level Level) (LevelSpec, bool) {
+	spec, found := levels.Load().specs[level]
+	return spec, found
+}
+
+// Levels returns the registered levels ordered by severity.
+func Levels() []LevelSpec {
+	specs := slices.Collect(maps.Values(levels.Load().specs))
+	slices.SortFunc(specs, func(a, b LevelSpec) int {
+		return cmp.Compare(a.Level, b.Level)
+	})
+	return specs
+}
+
+// String returns the level's name, or e.g. "level(42)"
+// if the level isn't registered.
 func (l Level) String() string {
-	return levelNames[l]
+	if spec, found := levels.Load().specs[l]; found {
+		return spec.Name
+	}
+	if l == LevelInvalid {
+		return ""
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
 }
 
 // MarshalJSON implementation.
@@ -60,14 +213,58 @@ func (l *Level) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// ParseLevel parses level string.
+// ParseLevel parses level string, the name or an alias of a registered level,
+// or the "level(42)" form returned by Level.String for unregistered levels.
 func ParseLevel(s string) (Level, error) {
-	l, ok := levelStrings[strings.ToLower(s)]
-	if !ok {
-		return LevelInvalid, ErrInvalidLevel
+	s = strings.ToLower(s)
+	l, ok := levels.Load().names[s]
+	if ok {
+		return l, nil
+	}
+
+	if v, found := strings.CutPrefix(s, "level("); found {
+		if v, found := strings.CutSuffix(v, ")"); found {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				return Level(n), nil
+			}
+		}
+	}
+
+	return LevelInvalid, ErrInvalidLevel
+}
+
+// ParseLevels parses a comma separated list of levels for named loggers, e.g.
+// "info,cache=debug,cache.fs=trace", into a default level and a map of levels
+// keyed by logger name, suitable for Options.Level and Options.NameLevels.
+// The default level is LevelInvalid if not set in s.
+func ParseLevels(s string) (Level, map[string]Level, error) {
+	var (
+		defaultLevel Level
+		nameLevels   = make(map[string]Level)
+	)
+
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, levelStr, found := strings.Cut(part, "=")
+		if !found {
+			levelStr, name = name, ""
+		}
+		level, err := ParseLevel(strings.TrimSpace(levelStr))
+		if err != nil {
+			return LevelInvalid, nil, fmt.Errorf("%w: %q", err, part)
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			defaultLevel = level
+		} else {
+			nameLevels[name] = level
+		}
 	}
 
-	return l, nil
+	return defaultLevel, nameLevels, nil
 }
 
 // MustParseLevel parses level string or panics.
@@ -79,3 +276,32 @@ func MustParseLevel(s string) Level {
 
 	return l
 }
+
+// LevelVar is a Level variable, to allow a logger's level to change dynamically.
+// It is safe for concurrent use.
+// The zero value of a LevelVar is LevelInvalid.
+type LevelVar struct {
+	v atomic.Int64
+}
+
+// NewLevelVar returns a new LevelVar set to level.
+func NewLevelVar(level Level) *LevelVar {
+	v := &LevelVar{}
+	v.Set(level)
+	return v
+}
+
+// Level returns v's level.
+func (v *LevelVar) Level() Level {
+	return Level(v.v.Load())
+}
+
+// Set sets v's level to level.
+func (v *LevelVar) Set(level Level) {
+	v.v.Store(int64(level))
+}
+
+// String implementation.
+func (v *LevelVar) String() string {
+	return v.Level().String()
+}
@@ -17,7 +17,12 @@ func TestParseLevel(t *testing.T) {
 		{"info", LevelInfo},
 		{"warn", LevelWarn},
 		{"warning", LevelWarn},
+		{"notice", LevelNotice},
 		{"error", LevelError},
+		{"critical", LevelCritical},
+		{"CRIT", LevelCritical},
+		{"panic", LevelPanic},
+		{"fatal", LevelFatal},
 	}
 
 	for _, c := range cases {
@@ -35,6 +40,78 @@ func TestParseLevel(t *testing.T) {
 	})
 }
 
+func TestRegisterLevel(t *testing.T) {
+	defer levels.Store(levels.Load())
+
+	const levelAudit Level = 45
+	err := RegisterLevel(LevelSpec{Level: levelAudit, Name: "audit", Aliases: []string{"security"}, Color: []int{35}, Symbol: "!"})
+	qt.Assert(t, err, qt.IsNil)
+
+	qt.Assert(t, levelAudit.String(), qt.Equals, "audit")
+	qt.Assert(t, MustParseLevel("Security"), qt.Equals, levelAudit)
+	spec, found := LookupLevel(levelAudit)
+	qt.Assert(t, found, qt.IsTrue)
+	qt.Assert(t, spec.Symbol, qt.Equals, "!")
+
+	var names []string
+	for _, spec := range Levels() {
+		names = append(names, spec.Name)
+	}
+	qt.Assert(t, names, qt.DeepEquals, []string{"trace", "debug", "info", "notice", "warn", "audit", "error", "critical", "panic", "fatal"})
+
+	b, err := json.Marshal(levelAudit)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, string(b), qt.Equals, `"audit"`)
+
+	for _, spec := range []LevelSpec{
+		{Level: LevelInvalid, Name: "zero"},
+		{Level: LevelWarn, Name: "warn2"},
+		{Level: 46, Name: "audit"},
+		{Level: 46, Name: "loud", Aliases: []string{"warning"}},
+		{Level: 46, Name: "Loud"},
+		{Level: 46, Name: "very loud"},
+		{Level: 46, Name: "loud", Aliases: []string{"loud"}},
+	} {
+		qt.Assert(t, RegisterLevel(spec), qt.IsNotNil, qt.Commentf("%+v", spec))
+	}
+	_, found = LookupLevel(46)
+	qt.Assert(t, found, qt.IsFalse)
+}
+
+func TestLevelStringUnregistered(t *testing.T) {
+	qt.Assert(t, Level(42).String(), qt.Equals, "level(42)")
+	qt.Assert(t, MustParseLevel("level(42)"), qt.Equals, Level(42))
+	for _, s := range []string{"level(0)", "level(-1)", "level(x)", "level(42", "level42)"} {
+		_, err := ParseLevel(s)
+		qt.Assert(t, err, qt.Equals, ErrInvalidLevel, qt.Commentf(s))
+	}
+
+	b, err := json.Marshal(Level(42))
+	qt.Assert(t, err, qt.IsNil)
+	var l Level
+	qt.Assert(t, json.Unmarshal(b, &l), qt.IsNil)
+	qt.Assert(t, l, qt.Equals, Level(42))
+	qt.Assert(t, LevelInvalid.String(), qt.Equals, "")
+	_, found := LookupLevel(42)
+	qt.Assert(t, found, qt.IsFalse)
+}
+
+func TestParseLevels(t *testing.T) {
+	level, nameLevels, err := ParseLevels("info, cache=debug,cache.fs=TRACE,")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, level, qt.Equals, LevelInfo)
+	qt.Assert(t, nameLevels, qt.DeepEquals, map[string]Level{"cache": LevelDebug, "cache.fs": LevelTrace})
+
+	level, nameLevels, err = ParseLevels("cache=warn")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, level, qt.Equals, LevelInvalid)
+	qt.Assert(t, nameLevels, qt.DeepEquals, map[string]Level{"cache": LevelWarn})
+
+	_, _, err = ParseLevels("info,cache=loud")
+	qt.Assert(t, err, qt.ErrorIs, ErrInvalidLevel)
+	qt.Assert(t, err, qt.ErrorMatches, `invalid level: "cache=loud"`)
+}
+
 func TestLevel_MarshalJSON(t *testing.T) {
 	e := Entry{
 		Message: "hello",
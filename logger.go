@@ -1,9 +1,17 @@
 package logg
 
 import (
+	"context"
 	"fmt"
 	stdlog "log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"weak"
 
 	"github.com/bep/clocks"
 )
@@ -44,9 +52,20 @@ func (f FieldsFunc) Fields() Fields {
 }
 
 // Field holds a named value.
+//
+// Fields created with the typed constructors (e.g. Int64 and Str) store
+// scalar values in a compact form to avoid allocations, and Value is nil.
+// Handlers should use Field.Any to get the value of any Field, or
+// Field.AppendValue to format it, and not read Value directly.
+// See the README for how this affects code written for earlier versions.
 type Field struct {
 	Name  string `json:"name"`
 	Value any    `json:"value"`
+
+	// Set by the typed constructors.
+	kind Kind
+	num  uint64
+	str  string
 }
 
 // Fields represents a slice of entry level data used for structured logging.
@@ -63,12 +82,66 @@ type Options struct {
 	// If not set, defaults to InfoLevel.
 	Level Level
 
+	// LevelVar, if set, holds the minimum level to log at and Level is ignored.
+	// This allows the level to be changed at runtime, which can also be
+	// done with Logger.SetLevel.
+	LevelVar *LevelVar
+
+	// NameLevels holds the minimum level to log at for named loggers
+	// (see Logger.Named), keyed by logger name or a dot separated prefix of it,
+	// e.g. "cache" or "cache.fs". The longest match wins.
+	// Named loggers without a match follow the level of their parent,
+	// including any changes made with Logger.SetLevel on the parent,
+	// until Logger.SetLevel is called on them.
+	// See ParseLevels.
+	NameLevels map[string]Level
+
 	// Handler is the log handler to use.
 	Handler Handler
 
 	// Clock is the clock to use for timestamps.
 	// If not set, the system clock is used.
 	Clock Clock
+
+	// AddSource, if set, records the source code location where the entry
+	// was logged in Entry.Source.
+	// This is only done for enabled log levels.
+	AddSource bool
+
+	// CallerSkip is the number of additional stack frames to skip when
+	// AddSource is set or a stack is captured (see StackLevel),
+	// e.g. 1 when logging via a helper function.
+	CallerSkip int
+
+	// StackLevel, if set, is the minimum level to capture the current
+	// goroutine's stack in Entry.Stack for.
+	// Entries with a stack from WithError keep that stack.
+	StackLevel Level
+
+	// ErrorHandler is called with the entry and the error when the Handler
+	// returns an error other than ErrStopLogEntry.
+	// The entry is only valid during the call, see Entry.Clone.
+	// If not set, DefaultErrorHandler is used.
+	// See RateLimitErrors to limit the number of errors reported.
+	ErrorHandler func(e *Entry, err error)
+
+	// ExitFunc is called to exit after an entry is logged at LevelFatal.
+	// If not set, os.Exit is used.
+	ExitFunc func(code int)
+
+	// ExitCode, if set, is the code passed to ExitFunc.
+	// If not set, 1 is used.
+	ExitCode *int
+
+	// ExitHooks are run in order after an entry is logged at LevelFatal,
+	// before ExitFunc is called, e.g. to close a database.
+	// More hooks can be added with Logger.RegisterExitHook.
+	ExitHooks []func()
+
+	// ExitTimeout is the maximum time to wait for the Handler to close
+	// before exiting.
+	// If not set, 5 seconds is used.
+	ExitTimeout time.Duration
 }
 
 // New returns a new logger.
@@ -77,30 +150,225 @@ func New(cfg Options) Logger {
 		panic("handler cannot be nil")
 	}
 
-	if cfg.Level <= 0 || cfg.Level > LevelError {
-		panic("log level is out of range")
+	if cfg.LevelVar == nil {
+		if cfg.Level == 0 {
+			cfg.Level = LevelInfo
+		}
+		cfg.LevelVar = NewLevelVar(cfg.Level)
+	}
+
+	checkLevel(cfg.LevelVar.Level())
+	for _, level := range cfg.NameLevels {
+		checkLevel(level)
 	}
 
 	if cfg.Clock == nil {
 		cfg.Clock = clocks.System()
 	}
 
-	if cfg.Level == 0 {
-		cfg.Level = LevelInfo
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = DefaultErrorHandler
+	}
+
+	if cfg.ExitFunc == nil {
+		cfg.ExitFunc = os.Exit
+	}
+	exitCode := 1
+	if cfg.ExitCode != nil {
+		exitCode = *cfg.ExitCode
+	}
+	if cfg.ExitTimeout == 0 {
+		cfg.ExitTimeout = 5 * time.Second
 	}
 
 	return &logger{
-		Handler: cfg.Handler,
-		Level:   cfg.Level,
-		Clock:   cfg.Clock,
+		Handler:    cfg.Handler,
+		level:      newLevelNode(cfg.LevelVar),
+		nameLevels: maps.Clone(cfg.NameLevels),
+		Clock:      cfg.Clock,
+		addSource:  cfg.AddSource,
+		callerSkip: cfg.CallerSkip,
+		stackLevel: cfg.StackLevel,
+
+		errorHandler: cfg.ErrorHandler,
+
+		exit: &exitState{
+			fn:      cfg.ExitFunc,
+			code:    exitCode,
+			hooks:   slices.Clone(cfg.ExitHooks),
+			timeout: cfg.ExitTimeout,
+		},
+	}
+}
+
+func checkLevel(level Level) {
+	if level <= LevelInvalid {
+		panic("log level is out of range")
 	}
 }
 
 // logger represents a logger with configurable Level and Handler.
 type logger struct {
 	Handler Handler
-	Level   Level
+	level   *levelNode
 	Clock   Clock
+
+	name       string
+	nameLevels map[string]Level
+
+	addSource  bool
+	callerSkip int
+	stackLevel Level
+
+	errorHandler func(e *Entry, err error)
+
+	exit *exitState
+}
+
+// exitState holds what to do after an entry is logged at LevelFatal,
+// shared by a logger and its named loggers.
+type exitState struct {
+	fn      func(code int)
+	code    int
+	timeout time.Duration
+
+	mu    sync.Mutex
+	hooks []func()
+}
+
+// levelMu guards the parent and followers of all levelNodes.
+var levelMu sync.Mutex
+
+// levelNode holds the level of a logger.
+// A named logger without a level of its own in Options.NameLevels follows
+// the level of its parent by sharing its LevelVar until its SetLevel is
+// called. Then it gets a LevelVar of its own, which is passed on to the
+// named loggers following it.
+type levelNode struct {
+	v atomic.Pointer[LevelVar]
+
+	// Guarded by levelMu.
+	parent    *levelNode // The node followed, if any.
+	followers []weak.Pointer[levelNode]
+}
+
+func newLevelNode(v *LevelVar) *levelNode {
+	n := &levelNode{}
+	n.v.Store(v)
+	return n
+}
+
+// follow returns a new node following n.
+func (n *levelNode) follow() *levelNode {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	f := &levelNode{parent: n}
+	f.v.Store(n.v.Load())
+	if len(n.followers) == cap(n.followers) {
+		// Remove the followers that are garbage collected before growing.
+		n.followers = slices.DeleteFunc(n.followers, func(w weak.Pointer[levelNode]) bool {
+			return w.Value() == nil
+		})
+	}
+	n.followers = append(n.followers, weak.Make(f))
+	return f
+}
+
+// Level returns n's level.
+func (n *levelNode) Level() Level {
+	return n.v.Load().Level()
+}
+
+// Set sets n's level, creating a LevelVar for n if it follows another node.
+func (n *levelNode) Set(level Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	if n.parent == nil {
+		n.v.Load().Set(level)
+		return
+	}
+	n.parent = nil
+	n.setVarLocked(NewLevelVar(level))
+}
+
+func (n *levelNode) setVarLocked(v *LevelVar) {
+	n.v.Store(v)
+	for _, w := range n.followers {
+		if f := w.Value(); f != nil && f.parent == n {
+			f.setVarLocked(v)
+		}
+	}
+}
+
+// Level returns the minimum level to log at.
+func (l *logger) Level() Level {
+	return l.level.Level()
+}
+
+// SetLevel sets the minimum level to log at.
+// For a named logger, this does not change the level of its parent
+// or siblings, see Options.NameLevels.
+func (l *logger) SetLevel(level Level) {
+	checkLevel(level)
+	l.level.Set(level)
+}
+
+// Named returns a child logger with name appended to l's name.
+func (l *logger) Named(name string) Logger {
+	if name == "" {
+		return l
+	}
+
+	child := *l
+	if l.name != "" {
+		child.name = l.name + "." + name
+	} else {
+		child.name = name
+	}
+
+	// Only create a new level if there's a more specific match than the parent's,
+	// so a parent's level set with SetLevel is inherited.
+	if match, level, found := l.lookupNameLevel(child.name); found && len(match) > len(l.name) {
+		child.level = newLevelNode(NewLevelVar(level))
+	} else {
+		child.level = l.level.follow()
+	}
+
+	return &child
+}
+
+// RegisterExitHook adds hook to the hooks run after an entry is logged at
+// LevelFatal, see Options.ExitHooks.
+func (l *logger) RegisterExitHook(hook func()) {
+	l.exit.mu.Lock()
+	defer l.exit.mu.Unlock()
+	l.exit.hooks = append(l.exit.hooks, hook)
+}
+
+// Flush flushes l's Handler, see Flush.
+func (l *logger) Flush(ctx context.Context) error {
+	return Flush(ctx, l.Handler)
+}
+
+// Close closes l's Handler, see Close.
+func (l *logger) Close(ctx context.Context) error {
+	return Close(ctx, l.Handler)
+}
+
+// lookupNameLevel finds the level of the longest prefix of name in l.nameLevels.
+func (l *logger) lookupNameLevel(name string) (string, Level, bool) {
+	for {
+		if level, found := l.nameLevels[name]; found {
+			return name, level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i == -1 {
+			return "", LevelInvalid, false
+		}
+		name = name[:i]
+	}
 }
 
 // Clock provides the current time.
@@ -139,21 +407,94 @@ func (l *logger) WithError(err error) *Entry {
 
 // ErrStopLogEntry is a sentinel error that can be returned from a
 // handler to stop the entry from being passed to the next handler.
+// It's not reported to Options.ErrorHandler.
 var ErrStopLogEntry = fmt.Errorf("stop log entry")
 
+// DefaultErrorHandler prints err using the standard library's log package.
+func DefaultErrorHandler(e *Entry, err error) {
+	stdlog.Printf("error logging: %s", err)
+}
+
+// RateLimitErrors returns an error handler that passes at most one error
+// per interval on to h.
+// The number of errors dropped since the last one passed on is added to the
+// message of the next error passed on.
+// Time is measured using the entries' timestamps.
+func RateLimitErrors(h func(e *Entry, err error), interval time.Duration) func(e *Entry, err error) {
+	var (
+		mu      sync.Mutex
+		last    time.Time
+		dropped int
+	)
+
+	return func(e *Entry, err error) {
+		mu.Lock()
+		if !last.IsZero() && e.Timestamp.Sub(last) < interval {
+			dropped++
+			mu.Unlock()
+			return
+		}
+		n := dropped
+		dropped = 0
+		last = e.Timestamp
+		mu.Unlock()
+
+		if n > 0 {
+			err = fmt.Errorf("%w (%d more errors dropped)", err, n)
+		}
+		h(e, err)
+	}
+}
+
 // log the message, invoking the handler.
 func (l *logger) log(e *Entry, s fmt.Stringer) {
-	if e.Level < l.Level {
+	if e.isLevelDisabled() {
+		if e.Level == LevelPanic || e.Level == LevelFatal {
+			// Panic and exit even if the entry isn't logged.
+			l.terminate(e, s.String())
+		}
 		return
 	}
 
 	finalized := objectPools.GetEntry()
 	defer objectPools.PutEntry(finalized)
 	e.finalize(finalized, s.String())
+	// Skip Entry.Log/Logf.
+	if l.addSource {
+		finalized.Source = callerFrame(l.callerSkip + 1)
+	}
+	if l.stackLevel != LevelInvalid && e.Level >= l.stackLevel && finalized.Stack == nil {
+		finalized.Stack = callers(l.callerSkip + 1)
+	}
 
-	if err := l.Handler.HandleLog(finalized); err != nil {
+	if err := HandleLogContext(finalized.Context(), l.Handler, finalized); err != nil {
 		if err != ErrStopLogEntry {
-			stdlog.Printf("error logging: %s", err)
+			l.errorHandler(finalized, err)
 		}
 	}
+
+	if finalized.Level == LevelPanic || finalized.Level == LevelFatal {
+		l.terminate(finalized, finalized.Message)
+	}
+}
+
+// terminate panics if e is at LevelPanic, or closes the Handler,
+// runs the exit hooks and exits if e is at LevelFatal.
+func (l *logger) terminate(e *Entry, msg string) {
+	if e.Level == LevelPanic {
+		panic(msg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.exit.timeout)
+	defer cancel()
+	if err := l.Close(ctx); err != nil {
+		l.errorHandler(e, err)
+	}
+	l.exit.mu.Lock()
+	hooks := slices.Clone(l.exit.hooks)
+	l.exit.mu.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+	l.exit.fn(l.exit.code)
 }
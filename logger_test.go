@@ -1,9 +1,15 @@
 package logg_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/bep/logg"
 	"github.com/bep/logg/handlers"
@@ -50,11 +56,164 @@ func TestLogger_levels(t *testing.T) {
 	qt.Assert(t, logg.LevelInfo, qt.Equals, e.Level)
 }
 
+func TestLogger_SetLevel(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+	debug := l.WithLevel(logg.LevelDebug)
+
+	debug.Log(logg.String("hidden"))
+	l.SetLevel(logg.LevelDebug)
+	qt.Assert(t, l.Level(), qt.Equals, logg.LevelDebug)
+	debug.WithField("a", "b").Log(logg.String("shown"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+	qt.Assert(t, h.Entries[0].Message, qt.Equals, "shown")
+	qt.Assert(t, func() { l.SetLevel(logg.LevelInvalid) }, qt.PanicMatches, "log level is out of range")
+}
+
+func TestLogger_LevelVar(t *testing.T) {
+	h := memory.New()
+	lv := logg.NewLevelVar(logg.LevelError)
+	l := logg.New(logg.Options{LevelVar: lv, Handler: h})
+	info := l.WithLevel(logg.LevelInfo)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			if i%2 == 0 {
+				lv.Set(logg.LevelInfo)
+			}
+			info.Logf("log %d", i)
+		})
+	}
+	wg.Wait()
+
+	qt.Assert(t, l.Level(), qt.Equals, logg.LevelInfo)
+	qt.Assert(t, lv.String(), qt.Equals, "info")
+	qt.Assert(t, len(h.Entries) > 0, qt.IsTrue)
+}
+
+func TestLogger_Named(t *testing.T) {
+	h := memory.New()
+	level, nameLevels, err := logg.ParseLevels("info,cache=debug,cache.fs=trace")
+	qt.Assert(t, err, qt.IsNil)
+	l := logg.New(logg.Options{Level: level, NameLevels: nameLevels, Handler: h})
+
+	build := l.Named("build")
+	cache := l.Named("cache")
+	cacheFs := cache.Named("fs")
+	cacheMem := cache.Named("mem")
+
+	qt.Assert(t, build.Level(), qt.Equals, logg.LevelInfo)
+	qt.Assert(t, cache.Level(), qt.Equals, logg.LevelDebug)
+	qt.Assert(t, cacheFs.Level(), qt.Equals, logg.LevelTrace)
+	qt.Assert(t, cacheMem.Level(), qt.Equals, logg.LevelDebug)
+	qt.Assert(t, l.Named("cache.fs.dir").Level(), qt.Equals, logg.LevelTrace)
+
+	build.WithLevel(logg.LevelDebug).Log(logg.String("build debug"))
+	cache.WithLevel(logg.LevelDebug).Log(logg.String("cache debug"))
+	cacheFs.WithLevel(logg.LevelTrace).Log(logg.String("cache fs trace"))
+	cacheMem.WithLevel(logg.LevelTrace).Log(logg.String("cache mem trace"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+	qt.Assert(t, h.Entries[0].LoggerName, qt.Equals, "cache")
+	qt.Assert(t, h.Entries[1].LoggerName, qt.Equals, "cache.fs")
+
+	// Loggers without a more specific match follow the parent's level.
+	cache.SetLevel(logg.LevelError)
+	qt.Assert(t, cacheMem.Level(), qt.Equals, logg.LevelError)
+	qt.Assert(t, cacheFs.Level(), qt.Equals, logg.LevelTrace)
+	qt.Assert(t, build.Level(), qt.Equals, logg.LevelInfo)
+	qt.Assert(t, l.Level(), qt.Equals, logg.LevelInfo)
+
+	// Setting the level of a child doesn't change the parent or siblings,
+	// and the child no longer follows the parent.
+	build.SetLevel(logg.LevelError)
+	qt.Assert(t, build.Level(), qt.Equals, logg.LevelError)
+	qt.Assert(t, l.Level(), qt.Equals, logg.LevelInfo)
+	qt.Assert(t, l.Named("other").Level(), qt.Equals, logg.LevelInfo)
+	l.SetLevel(logg.LevelWarn)
+	qt.Assert(t, build.Level(), qt.Equals, logg.LevelError)
+	qt.Assert(t, l.Named("other").Level(), qt.Equals, logg.LevelWarn)
+
+	// Named loggers follow the logger they were created from, also
+	// after it gets a level of its own.
+	web := l.Named("web")
+	webAPI := web.Named("api")
+	qt.Assert(t, webAPI.Level(), qt.Equals, logg.LevelWarn)
+	web.SetLevel(logg.LevelDebug)
+	qt.Assert(t, webAPI.Level(), qt.Equals, logg.LevelDebug)
+	l.SetLevel(logg.LevelInfo)
+	qt.Assert(t, webAPI.Level(), qt.Equals, logg.LevelDebug)
+
+	// NameLevels is copied.
+	nameLevels["build"] = logg.LevelError
+	qt.Assert(t, l.Named("build").Level(), qt.Equals, logg.LevelInfo)
+}
+
+func TestLogger_NamedLevelVar(t *testing.T) {
+	v := logg.NewLevelVar(logg.LevelInfo)
+	l := logg.New(logg.Options{LevelVar: v, Handler: handlers.Discard})
+	a := l.Named("a").Named("b")
+
+	v.Set(logg.LevelError)
+	qt.Assert(t, a.Level(), qt.Equals, logg.LevelError)
+}
+
+func TestLogger_AddSource(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h, AddSource: true})
+	info := l.WithLevel(logg.LevelInfo)
+
+	info.Log(logg.String("log"))
+	_, _, line, _ := runtime.Caller(0)
+	info.Logf("logf")
+	l.WithLevel(logg.LevelDebug).Log(logg.String("disabled"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+	qt.Assert(t, h.Entries[0].Source.Function, qt.Equals, "github.com/bep/logg_test.TestLogger_AddSource")
+	qt.Assert(t, filepath.Base(h.Entries[0].Source.File), qt.Equals, "logger_test.go")
+	qt.Assert(t, h.Entries[0].Source.Line, qt.Equals, line-1)
+	qt.Assert(t, h.Entries[1].Source.Line, qt.Equals, line+1)
+}
+
+func TestLogger_AddSourceCallerSkip(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h, AddSource: true, CallerSkip: 1})
+	logHelper := func(msg string) {
+		l.WithLevel(logg.LevelInfo).Log(logg.String(msg))
+	}
+
+	logHelper("hello")
+	_, _, line, _ := runtime.Caller(0)
+
+	qt.Assert(t, h.Entries[0].Source.Line, qt.Equals, line-1)
+}
+
+func TestLogger_WithoutSource(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+	l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+	qt.Assert(t, h.Entries[0].Source, qt.Equals, logg.Frame{})
+}
+
+func TestLogger_StackLevel(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h, StackLevel: logg.LevelError})
+
+	l.WithLevel(logg.LevelInfo).Log(logg.String("no stack"))
+	l.WithLevel(logg.LevelError).Log(logg.String("stack"))
+
+	qt.Assert(t, h.Entries, qt.HasLen, 2)
+	qt.Assert(t, h.Entries[0].Stack, qt.IsNil)
+	qt.Assert(t, h.Entries[1].Stack[0].Function, qt.Equals, "github.com/bep/logg_test.TestLogger_StackLevel")
+}
+
 func TestLogger_WithFields(t *testing.T) {
 	h := memory.New()
 	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
 
-	info := l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{{"file", "sloth.png"}})
+	info := l.WithLevel(logg.LevelInfo).WithFields(logg.Fields{{Name: "file", Value: "sloth.png"}})
 	info.WithLevel(logg.LevelDebug).Log(logg.String("uploading"))
 	info.Log(logg.String("upload complete"))
 
@@ -63,7 +222,7 @@ func TestLogger_WithFields(t *testing.T) {
 	e := h.Entries[0]
 	qt.Assert(t, "upload complete", qt.Equals, e.Message)
 	qt.Assert(t, logg.LevelInfo, qt.Equals, e.Level)
-	qt.Assert(t, e.Fields, qt.DeepEquals, logg.Fields{{"file", "sloth.png"}})
+	qt.Assert(t, e.Fields, qt.DeepEquals, logg.Fields{{Name: "file", Value: "sloth.png"}})
 }
 
 func TestLogger_WithField(t *testing.T) {
@@ -79,7 +238,7 @@ func TestLogger_WithField(t *testing.T) {
 	e := h.Entries[0]
 	qt.Assert(t, "upload complete", qt.Equals, e.Message)
 	qt.Assert(t, logg.LevelInfo, qt.Equals, e.Level)
-	qt.Assert(t, e.Fields, qt.DeepEquals, logg.Fields{{"file", "sloth.png"}, {"user", "Tobi"}})
+	qt.Assert(t, e.Fields, qt.DeepEquals, logg.Fields{{Name: "file", Value: "sloth.png"}, {Name: "user", Value: "Tobi"}})
 }
 
 func TestLogger_HandlerFunc(t *testing.T) {
@@ -97,6 +256,210 @@ func TestLogger_HandlerFunc(t *testing.T) {
 	qt.Assert(t, logg.LevelInfo, qt.Equals, e.Level)
 }
 
+func TestLogger_FlushClose(t *testing.T) {
+	h := &lifecycleHandler{}
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	qt.Assert(t, l.Flush(context.Background()), qt.IsNil)
+	qt.Assert(t, l.Named("cache").Close(context.Background()), qt.IsNil)
+	qt.Assert(t, h.calls, qt.DeepEquals, []string{"flush", "close"})
+
+	// Handlers not implementing Flusher or Closer are left alone.
+	l = logg.New(logg.Options{Level: logg.LevelInfo, Handler: handlers.Discard})
+	qt.Assert(t, l.Flush(context.Background()), qt.IsNil)
+	qt.Assert(t, l.Close(context.Background()), qt.IsNil)
+}
+
+func TestClose(t *testing.T) {
+	// Close falls back to Flush.
+	f := &flushHandler{}
+	qt.Assert(t, logg.Close(context.Background(), f), qt.IsNil)
+	qt.Assert(t, f.flushed, qt.IsTrue)
+
+	errFlush := errors.New("flush failed")
+	errClose := errors.New("close failed")
+	h1 := &lifecycleHandler{flushErr: errFlush, closeErr: errClose}
+	h2 := &lifecycleHandler{}
+
+	qt.Assert(t, logg.FlushAll(context.Background(), h1, handlers.Discard, h2), qt.ErrorIs, errFlush)
+	qt.Assert(t, logg.CloseAll(context.Background(), h1, handlers.Discard, h2), qt.ErrorIs, errClose)
+	qt.Assert(t, h2.calls, qt.DeepEquals, []string{"flush", "close"})
+}
+
+type flushHandler struct {
+	flushed bool
+}
+
+func (h *flushHandler) HandleLog(e *logg.Entry) error {
+	return nil
+}
+
+func (h *flushHandler) Flush(ctx context.Context) error {
+	h.flushed = true
+	return nil
+}
+
+type lifecycleHandler struct {
+	calls    []string
+	flushErr error
+	closeErr error
+}
+
+func (h *lifecycleHandler) HandleLog(e *logg.Entry) error {
+	h.calls = append(h.calls, "log "+e.Message)
+	return nil
+}
+
+func (h *lifecycleHandler) Flush(ctx context.Context) error {
+	h.calls = append(h.calls, "flush")
+	return h.flushErr
+}
+
+func (h *lifecycleHandler) Close(ctx context.Context) error {
+	h.calls = append(h.calls, "close")
+	return h.closeErr
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	h := &lifecycleHandler{}
+	code := 3
+	l := logg.New(logg.Options{
+		Level:    logg.LevelInfo,
+		Handler:  h,
+		ExitCode: &code,
+		ExitHooks: []func(){
+			func() { h.calls = append(h.calls, "hook1") },
+			func() { h.calls = append(h.calls, "hook2") },
+		},
+		ExitFunc: func(code int) {
+			h.calls = append(h.calls, fmt.Sprintf("exit %d", code))
+		},
+	})
+
+	l.Named("db").RegisterExitHook(func() { h.calls = append(h.calls, "hook3") })
+
+	l.WithLevel(logg.LevelFatal).WithField("db", "down").Log(logg.String("giving up"))
+	qt.Assert(t, h.calls, qt.DeepEquals, []string{"log giving up", "close", "hook1", "hook2", "hook3", "exit 3"})
+
+	// Exit even if the entry isn't logged.
+	h.calls = nil
+	l.SetLevel(logg.LevelFatal)
+	l.WithLevel(logg.LevelError).Log(logg.String("not logged"))
+	l.WithLevel(logg.LevelFatal).Logf("giving up %d", 2)
+	qt.Assert(t, h.calls, qt.DeepEquals, []string{"log giving up 2", "close", "hook1", "hook2", "hook3", "exit 3"})
+
+	// Close errors are passed to the ErrorHandler.
+	var errs []error
+	var codes []int
+	l = logg.New(logg.Options{
+		Level:        logg.LevelInfo,
+		Handler:      &lifecycleHandler{closeErr: errors.New("close failed")},
+		ErrorHandler: func(e *logg.Entry, err error) { errs = append(errs, err) },
+		ExitFunc:     func(code int) { codes = append(codes, code) },
+	})
+	l.WithLevel(logg.LevelFatal).Log(logg.String("giving up"))
+	qt.Assert(t, errs, qt.HasLen, 1)
+	qt.Assert(t, errs[0], qt.ErrorMatches, "close failed")
+
+	// The exit code defaults to 1, but can be set to 0.
+	zero := 0
+	l = logg.New(logg.Options{
+		Level:    logg.LevelInfo,
+		Handler:  handlers.Discard,
+		ExitCode: &zero,
+		ExitFunc: func(code int) { codes = append(codes, code) },
+	})
+	l.WithLevel(logg.LevelFatal).Log(logg.String("done"))
+	qt.Assert(t, codes, qt.DeepEquals, []int{1, 0})
+}
+
+func TestLogger_RegisterExitHookConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	var n int
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: handlers.Discard, ExitFunc: func(code int) {}})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			l.Named("a").RegisterExitHook(func() {
+				mu.Lock()
+				n++
+				mu.Unlock()
+			})
+			l.WithLevel(logg.LevelInfo).Log(logg.String("hello"))
+		})
+	}
+	wg.Wait()
+
+	l.WithLevel(logg.LevelFatal).Log(logg.String("bye"))
+	qt.Assert(t, n, qt.Equals, 10)
+}
+
+func TestLogger_Panic(t *testing.T) {
+	h := memory.New()
+	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+
+	qt.Assert(t, func() {
+		l.WithLevel(logg.LevelPanic).WithField("user", "tj").Logf("bad state %d", 42)
+	}, qt.PanicMatches, "bad state 42")
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+	qt.Assert(t, h.Entries[0].Level, qt.Equals, logg.LevelPanic)
+	qt.Assert(t, h.Entries[0].Message, qt.Equals, "bad state 42")
+
+	// Panic even if the entry isn't logged.
+	l.SetLevel(logg.LevelFatal)
+	qt.Assert(t, func() {
+		l.WithLevel(logg.LevelPanic).Log(logg.String("not logged"))
+	}, qt.PanicMatches, "not logged")
+	qt.Assert(t, h.Entries, qt.HasLen, 1)
+}
+
+func TestLogger_ErrorHandler(t *testing.T) {
+	var errs []error
+	var messages []string
+	h := logg.HandlerFunc(func(e *logg.Entry) error {
+		switch e.Message {
+		case "stop":
+			return logg.ErrStopLogEntry
+		case "fail":
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+	l := logg.New(logg.Options{
+		Level:   logg.LevelInfo,
+		Handler: h,
+		ErrorHandler: func(e *logg.Entry, err error) {
+			messages = append(messages, e.Message)
+			errs = append(errs, err)
+		},
+	})
+	info := l.WithLevel(logg.LevelInfo)
+
+	info.Log(logg.String("ok"))
+	info.Log(logg.String("stop"))
+	info.Log(logg.String("fail"))
+
+	qt.Assert(t, messages, qt.DeepEquals, []string{"fail"})
+	qt.Assert(t, errs, qt.HasLen, 1)
+	qt.Assert(t, errs[0], qt.ErrorMatches, "handler failed")
+}
+
+func TestRateLimitErrors(t *testing.T) {
+	var errs []string
+	eh := logg.RateLimitErrors(func(e *logg.Entry, err error) {
+		errs = append(errs, err.Error())
+	}, time.Minute)
+
+	errFail := errors.New("fail")
+	start := time.Date(2022, 8, 12, 10, 0, 0, 0, time.UTC)
+	for _, d := range []time.Duration{0, time.Second, 30 * time.Second, time.Minute, 2 * time.Minute} {
+		eh(&logg.Entry{Timestamp: start.Add(d)}, errFail)
+	}
+
+	qt.Assert(t, errs, qt.DeepEquals, []string{"fail", "fail (2 more errors dropped)", "fail"})
+}
+
 func BenchmarkLogger_small(b *testing.B) {
 	l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: handlers.Discard})
 	info := l.WithLevel(logg.LevelInfo)
@@ -112,9 +475,9 @@ func BenchmarkLogger_medium(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		info.WithFields(logg.Fields{
-			{"file", "sloth.png"},
-			{"type", "image/png"},
-			{"size", 1 << 20},
+			{Name: "file", Value: "sloth.png"},
+			{Name: "type", Value: "image/png"},
+			{Name: "size", Value: 1 << 20},
 		}).Log(logg.String("upload"))
 	}
 }
@@ -127,17 +490,17 @@ func BenchmarkLogger_large(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		info.WithFields(logg.Fields{
-			{"file", "sloth.png"},
-			{"type", "image/png"},
-			{"size", 1 << 20},
+			{Name: "file", Value: "sloth.png"},
+			{Name: "type", Value: "image/png"},
+			{Name: "size", Value: 1 << 20},
 		}).
 			WithFields(logg.Fields{
-				{"some", "more"},
-				{"data", "here"},
-				{"whatever", "blah blah"},
-				{"more", "stuff"},
-				{"context", "such useful"},
-				{"much", "fun"},
+				{Name: "some", Value: "more"},
+				{Name: "data", Value: "here"},
+				{Name: "whatever", Value: "blah blah"},
+				{Name: "more", Value: "stuff"},
+				{Name: "context", Value: "such useful"},
+				{Name: "much", Value: "fun"},
 			}).
 			WithError(err).Log(logg.String("upload failed"))
 	}
@@ -187,7 +550,7 @@ func BenchmarkLogger_context_many_fields_duplicate_names_with_fields(b *testing.
 	for i := 0; i < b.N; i++ {
 		info := l.WithLevel(logg.LevelInfo)
 		for range 3333 {
-			info = info.WithFields(logg.Fields{{"name", "value"}, {"name", "value"}, {"name", "value"}})
+			info = info.WithFields(logg.Fields{{Name: "name", Value: "value"}, {Name: "name", Value: "value"}, {Name: "name", Value: "value"}})
 		}
 		info.Log(logg.String("upload"))
 	}
@@ -0,0 +1,59 @@
+package logg
+
+import (
+	"fmt"
+	"io"
+)
+
+// assert interface compliance.
+var (
+	_ fmt.Stringer   = SecretValue{}
+	_ fmt.GoStringer = SecretValue{}
+	_ fmt.Formatter  = SecretValue{}
+)
+
+const secretMask = "***"
+
+// SecretValue holds a sensitive value, e.g. a password or a token.
+// It's always rendered as "***", both by the built-in handlers and
+// when formatted with fmt or marshaled as JSON or text.
+type SecretValue struct {
+	v any
+}
+
+// Secret wraps v so it's rendered as "***", e.g.
+//
+//	l.WithField("token", logg.Secret(token))
+func Secret(v any) SecretValue {
+	return SecretValue{v: v}
+}
+
+// Value returns the wrapped value.
+func (s SecretValue) Value() any {
+	return s.v
+}
+
+// String implements fmt.Stringer.
+func (s SecretValue) String() string {
+	return secretMask
+}
+
+// GoString implements fmt.GoStringer.
+func (s SecretValue) GoString() string {
+	return secretMask
+}
+
+// Format implements fmt.Formatter, so "***" is written for all verbs.
+func (s SecretValue) Format(f fmt.State, verb rune) {
+	io.WriteString(f, secretMask)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s SecretValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + secretMask + `"`), nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s SecretValue) MarshalText() ([]byte, error) {
+	return []byte(secretMask), nil
+}
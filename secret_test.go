@@ -0,0 +1,44 @@
+package logg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bep/logg"
+	jsonh "github.com/bep/logg/handlers/json"
+	"github.com/bep/logg/handlers/text"
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSecret(t *testing.T) {
+	s := logg.Secret("hunter2")
+
+	qt.Assert(t, s.Value(), qt.Equals, "hunter2")
+	qt.Assert(t, s.String(), qt.Equals, "***")
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		qt.Assert(t, fmt.Sprintf(format, s), qt.Equals, "***", qt.Commentf(format))
+	}
+
+	b, err := json.Marshal(map[string]any{"password": s})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, string(b), qt.Equals, `{"password":"***"}`)
+
+	b, err = logg.Any("password", s).MarshalJSON()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, string(b), qt.Equals, `{"name":"password","value":"***"}`)
+	qt.Assert(t, string(logg.Any("password", s).AppendValue(nil)), qt.Equals, "***")
+}
+
+func TestSecretHandlers(t *testing.T) {
+	var jsonBuf, textBuf bytes.Buffer
+	for _, h := range []logg.Handler{jsonh.New(&jsonBuf), text.New(&textBuf, text.Options{})} {
+		l := logg.New(logg.Options{Level: logg.LevelInfo, Handler: h})
+		l.WithLevel(logg.LevelInfo).WithField("token", logg.Secret("abc")).Log(logg.String("login"))
+	}
+
+	qt.Assert(t, jsonBuf.String(), qt.Contains, `"fields":[{"name":"token","value":"***"}]`)
+	qt.Assert(t, textBuf.String(), qt.Contains, "token=***")
+	qt.Assert(t, jsonBuf.String()+textBuf.String(), qt.Not(qt.Contains), "abc")
+}
@@ -1,8 +1,139 @@
 package logg
 
-import "github.com/pkg/errors"
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+)
 
-// stackTracer interface.
-type stackTracer interface {
-	StackTrace() errors.StackTrace
+// Frame describes a location in the source code.
+type Frame struct {
+	// Function is the package path-qualified function name.
+	Function string `json:"function"`
+
+	// File is the absolute path to the source file.
+	File string `json:"file"`
+
+	// Line is the line number in File.
+	Line int `json:"line"`
+}
+
+// String returns the location on the form "file:line".
+func (f Frame) String() string {
+	return f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// callerFrame returns the Frame of the caller of the function calling
+// callerFrame, skipping skip additional stack frames.
+func callerFrame(skip int) Frame {
+	var pcs [1]uintptr
+	// Skip runtime.Callers, callerFrame and its caller.
+	if runtime.Callers(skip+3, pcs[:]) == 0 {
+		return Frame{}
+	}
+	f, _ := runtime.CallersFrames(pcs[:]).Next()
+	return Frame{
+		Function: f.Function,
+		File:     f.File,
+		Line:     f.Line,
+	}
+}
+
+// StackTracer can be implemented by errors carrying the call stack where
+// they were created, as program counters as returned by runtime.Callers.
+type StackTracer interface {
+	Callers() []uintptr
+}
+
+// maxStackDepth is the maximum number of frames captured by callers.
+const maxStackDepth = 32
+
+// callers returns the stack of the caller of the function calling
+// callers, skipping skip additional stack frames.
+func callers(skip int) []Frame {
+	var pcs [maxStackDepth]uintptr
+	// Skip runtime.Callers, callers and its caller.
+	n := runtime.Callers(skip+3, pcs[:])
+	return framesFromPCs(pcs[:n])
+}
+
+func framesFromPCs(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	frames := make([]Frame, 0, len(pcs))
+	it := runtime.CallersFrames(pcs)
+	for {
+		f, more := it.Next()
+		frames = append(frames, Frame{
+			Function: f.Function,
+			File:     f.File,
+			Line:     f.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// errorStack returns the deepest stack found in err's tree,
+// see errors.Unwrap and errors.Join.
+func errorStack(err error) []Frame {
+	pcs, _ := deepestStack(err, 0)
+	return framesFromPCs(pcs)
+}
+
+func deepestStack(err error, depth int) ([]uintptr, int) {
+	if err == nil {
+		return nil, -1
+	}
+
+	pcs, d := errorCallers(err), -1
+	if pcs != nil {
+		d = depth
+	}
+
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if p, pd := deepestStack(x.Unwrap(), depth+1); pd > d {
+			pcs, d = p, pd
+		}
+	case interface{ Unwrap() []error }:
+		for _, err := range x.Unwrap() {
+			if p, pd := deepestStack(err, depth+1); pd > d {
+				pcs, d = p, pd
+			}
+		}
+	}
+
+	return pcs, d
+}
+
+// errorCallers returns the program counters of err's own stack, if any.
+func errorCallers(err error) []uintptr {
+	if x, ok := err.(StackTracer); ok {
+		return x.Callers()
+	}
+	return stackTraceCallers(err)
+}
+
+// stackTraceCallers returns the program counters from err's StackTrace
+// method if it returns a slice of uintptr based frames, e.g. the errors in
+// github.com/pkg/errors, without depending on that package.
+func stackTraceCallers(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
 }